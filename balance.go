@@ -0,0 +1,180 @@
+package gqlclient
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// BalancePolicy describes how a client picks an endpoint among several.
+type BalancePolicy int
+
+const (
+	// BalanceRoundRobin cycles through the endpoints in order.
+	BalanceRoundRobin BalancePolicy = iota
+	// BalanceLeastLatency picks the endpoint with the lowest observed
+	// latency.
+	BalanceLeastLatency
+)
+
+// BalanceOptions contains options for NewBalanced.
+type BalanceOptions struct {
+	// Policy used to pick an endpoint for each request.
+	Policy BalancePolicy
+	// Number of consecutive failures after which an endpoint is ejected.
+	// Defaults to 1.
+	MaxFailures int
+	// Duration during which an ejected endpoint is skipped. Defaults to 30
+	// seconds.
+	EjectDuration time.Duration
+}
+
+// NewBalanced creates a new GraphQL client which spreads requests across
+// multiple replicated endpoints.
+//
+// Endpoints which fail to respond, or respond with an HTTP 5xx status, are
+// temporarily ejected. Query operations without uploads are idempotent, so
+// they are automatically retried on another endpoint on failure.
+//
+// If hc is nil, http.DefaultClient is used. If options is nil, default options
+// are used.
+func NewBalanced(endpoints []string, hc *http.Client, options *BalanceOptions) *Client {
+	if len(endpoints) == 0 {
+		panic("gqlclient: NewBalanced called without any endpoint")
+	}
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	if options == nil {
+		options = new(BalanceOptions)
+	}
+
+	pool := &endpointPool{
+		policy:        options.Policy,
+		maxFailures:   options.MaxFailures,
+		ejectDuration: options.EjectDuration,
+	}
+	if pool.maxFailures <= 0 {
+		pool.maxFailures = 1
+	}
+	if pool.ejectDuration <= 0 {
+		pool.ejectDuration = 30 * time.Second
+	}
+	for _, u := range endpoints {
+		pool.endpoints = append(pool.endpoints, &endpoint{url: u})
+	}
+
+	return &Client{
+		endpoints: pool,
+		http:      hc,
+	}
+}
+
+type endpoint struct {
+	url string
+
+	// Protected by endpointPool.mutex
+	latency      time.Duration
+	failures     int
+	ejectedUntil time.Time
+}
+
+type endpointPool struct {
+	policy        BalancePolicy
+	maxFailures   int
+	ejectDuration time.Duration
+	endpoints     []*endpoint
+
+	mutex sync.Mutex
+	next  int
+}
+
+func (pool *endpointPool) len() int {
+	return len(pool.endpoints)
+}
+
+// pick selects an endpoint which isn't part of the tried set. Healthy
+// endpoints are preferred over ejected ones.
+func (pool *endpointPool) pick(tried map[*endpoint]bool) *endpoint {
+	if len(pool.endpoints) == 1 {
+		return pool.endpoints[0]
+	}
+
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	now := time.Now()
+	var best, fallback *endpoint
+	n := len(pool.endpoints)
+	for i := 0; i < n; i++ {
+		ep := pool.endpoints[(pool.next+i)%n]
+		if tried[ep] {
+			continue
+		}
+		if now.Before(ep.ejectedUntil) {
+			if fallback == nil || ep.ejectedUntil.Before(fallback.ejectedUntil) {
+				fallback = ep
+			}
+			continue
+		}
+		if best == nil {
+			best = ep
+			if pool.policy == BalanceRoundRobin {
+				break
+			}
+		} else if ep.latency < best.latency {
+			best = ep
+		}
+	}
+	pool.next = (pool.next + 1) % n
+
+	if best == nil {
+		best = fallback
+	}
+	return best
+}
+
+// report records the outcome of a request sent to an endpoint.
+func (pool *endpointPool) report(ep *endpoint, latency time.Duration, failed bool) {
+	if len(pool.endpoints) == 1 {
+		return
+	}
+
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	if failed {
+		ep.failures++
+		if ep.failures >= pool.maxFailures {
+			ep.ejectedUntil = time.Now().Add(pool.ejectDuration)
+		}
+		return
+	}
+
+	ep.failures = 0
+	ep.ejectedUntil = time.Time{}
+	if ep.latency == 0 {
+		ep.latency = latency
+	} else {
+		// Exponentially weighted moving average
+		ep.latency = (3*ep.latency + latency) / 4
+	}
+}
+
+// isIdempotent checks whether a GraphQL query document only contains query
+// operations.
+func isIdempotent(query string) bool {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil || len(doc.Operations) == 0 {
+		return false
+	}
+	for _, op := range doc.Operations {
+		if op.Operation != ast.Query {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+package gqlclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestPool(policy BalancePolicy, urls ...string) *endpointPool {
+	pool := &endpointPool{
+		policy:        policy,
+		maxFailures:   1,
+		ejectDuration: time.Minute,
+	}
+	for _, u := range urls {
+		pool.endpoints = append(pool.endpoints, &endpoint{url: u})
+	}
+	return pool
+}
+
+func TestEndpointPoolRoundRobin(t *testing.T) {
+	pool := newTestPool(BalanceRoundRobin, "a", "b", "c")
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		got = append(got, pool.pick(nil).url)
+	}
+	want := []string{"a", "b", "c", "a"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("pick() order = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestEndpointPoolLeastLatency(t *testing.T) {
+	pool := newTestPool(BalanceLeastLatency, "a", "b", "c")
+	pool.report(pool.endpoints[0], 30*time.Millisecond, false)
+	pool.report(pool.endpoints[1], 10*time.Millisecond, false)
+	pool.report(pool.endpoints[2], 20*time.Millisecond, false)
+
+	for i := 0; i < 3; i++ {
+		if ep := pool.pick(nil); ep.url != "b" {
+			t.Errorf("pick() = %q, want %q", ep.url, "b")
+		}
+	}
+
+	tried := map[*endpoint]bool{pool.endpoints[1]: true}
+	if ep := pool.pick(tried); ep.url != "c" {
+		t.Errorf("pick() without b = %q, want %q", ep.url, "c")
+	}
+}
+
+func TestEndpointPoolEjection(t *testing.T) {
+	pool := newTestPool(BalanceRoundRobin, "a", "b")
+	pool.maxFailures = 2
+
+	a := pool.endpoints[0]
+	pool.report(a, 0, true)
+	if !a.ejectedUntil.IsZero() {
+		t.Fatalf("endpoint ejected after a single failure")
+	}
+	pool.report(a, 0, true)
+	if a.ejectedUntil.IsZero() {
+		t.Fatalf("endpoint not ejected after two failures")
+	}
+
+	for i := 0; i < 3; i++ {
+		if ep := pool.pick(nil); ep != pool.endpoints[1] {
+			t.Errorf("pick() = %q, want %q", ep.url, "b")
+		}
+	}
+
+	// Ejected endpoints are used as a last resort
+	tried := map[*endpoint]bool{pool.endpoints[1]: true}
+	if ep := pool.pick(tried); ep != a {
+		t.Errorf("pick() without b = %q, want %q", ep.url, "a")
+	}
+
+	pool.report(a, time.Millisecond, false)
+	if a.failures != 0 || !a.ejectedUntil.IsZero() {
+		t.Errorf("endpoint still ejected after a success")
+	}
+}
+
+func newTestEndpoint(t *testing.T, status int, hits *int32) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(hits, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"data":{"ok":true}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestClientFailover(t *testing.T) {
+	var badHits, goodHits int32
+	c := NewBalanced([]string{
+		newTestEndpoint(t, http.StatusBadGateway, &badHits),
+		newTestEndpoint(t, http.StatusOK, &goodHits),
+	}, nil, nil)
+
+	var data struct {
+		OK bool
+	}
+	if err := c.Execute(context.Background(), NewOperation("query { ok }"), &data); err != nil {
+		t.Fatalf("Execute() = %v", err)
+	}
+	if !data.OK || badHits != 1 || goodHits != 1 {
+		t.Errorf("got ok=%v, bad hits=%v, good hits=%v", data.OK, badHits, goodHits)
+	}
+	if bad := c.endpoints.endpoints[0]; bad.ejectedUntil.IsZero() {
+		t.Errorf("failing endpoint not ejected")
+	}
+
+	// Mutations aren't retried
+	err := c.Execute(context.Background(), NewOperation("mutation { ok }"), &data)
+	if err != nil || goodHits != 2 || badHits != 1 {
+		t.Errorf("Execute(mutation) = %v, bad hits=%v, good hits=%v", err, badHits, goodHits)
+	}
+}
+
+func TestClientCanceledContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-time.After(200 * time.Millisecond):
+		}
+	}))
+	defer srv.Close()
+
+	c := NewBalanced([]string{srv.URL, srv.URL}, nil, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if err := c.Execute(ctx, NewOperation("query { ok }"), nil); err == nil {
+		t.Fatalf("Execute() succeeded despite the deadline")
+	}
+
+	for _, ep := range c.endpoints.endpoints {
+		if ep.failures != 0 || !ep.ejectedUntil.IsZero() {
+			t.Errorf("endpoint ejected after the caller's deadline expired")
+		}
+	}
+}
@@ -8,25 +8,44 @@ import (
 	"io"
 	"mime"
 	"net/http"
+	"sync"
+	"time"
 )
 
 // Client is a GraphQL HTTP client.
 type Client struct {
-	endpoint string
-	http     *http.Client
+	// RequestEncoding, if non-nil, is used to compress request bodies.
+	// Multipart requests with uploads are always sent uncompressed.
+	RequestEncoding *Encoding
+	// ResponseEncodings lists the content codings accepted for response
+	// bodies. If empty, compression is left to the HTTP transport.
+	ResponseEncodings []*Encoding
+
+	// MaxResponseSize is the maximum size of a response body in bytes, after
+	// decompression. Zero means no limit.
+	MaxResponseSize int64
+	// MaxResponseDepth is the maximum nesting depth of JSON values in a
+	// response body. Zero means no limit.
+	MaxResponseDepth int
+	// MaxResponseErrors is the maximum number of GraphQL errors in a
+	// response. Zero means no limit.
+	MaxResponseErrors int
+
+	// InjectTypename adds a __typename field to all nested selection sets of
+	// queries before sending them. Without a schema, the client cannot tell
+	// abstract types apart, so __typename is requested for all objects. See
+	// the InjectTypename function.
+	InjectTypename bool
+
+	endpoints *endpointPool
+	http      *http.Client
 }
 
 // New creates a new GraphQL client with the specified endpoint.
 //
 // If hc is nil, http.DefaultClient is used.
 func New(endpoint string, hc *http.Client) *Client {
-	if hc == nil {
-		hc = http.DefaultClient
-	}
-	return &Client{
-		endpoint: endpoint,
-		http:     hc,
-	}
+	return NewBalanced([]string{endpoint}, hc, nil)
 }
 
 // Operation describes a GraphQL operation.
@@ -36,6 +55,13 @@ type Operation struct {
 	query   string
 	vars    map[string]interface{}
 	uploads map[string]Upload
+
+	// Query with __typename injected, for Client.InjectTypename
+	typename struct {
+		once  sync.Once
+		query string
+		err   error
+	}
 }
 
 // NewOperation creates a new GraphQL operation.
@@ -82,45 +108,133 @@ func (op *Operation) Var(k string, v interface{}) {
 //
 // The data returned by the server will be decoded into the data argument.
 func (c *Client) Execute(ctx context.Context, op *Operation, data interface{}) error {
+	resp, body, err := c.do(ctx, op)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	// io.TeeReader(body, os.Stderr)
+	var errs []Error
+	if err := c.decodeResponse(json.NewDecoder(body), data, &errs); err != nil {
+		return decodeError(err)
+	}
+
+	return responseError(resp, errs)
+}
+
+// decodeResponse decodes a GraphQL response. The data is decoded into data,
+// and the GraphQL errors are appended to errs.
+func (c *Client) decodeResponse(dec *json.Decoder, data interface{}, errs *[]Error) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		key, err := readKey(dec)
+		if err != nil {
+			return err
+		}
+		switch {
+		case key == "data" && data != nil:
+			err = dec.Decode(data)
+		case key == "errors":
+			err = decodeErrorList(dec, errs, c.MaxResponseErrors)
+		default:
+			err = skipValue(dec)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// do sends the operation to the GraphQL server and returns the HTTP response
+// along with its decoded JSON body. The caller is responsible for closing the
+// body.
+func (c *Client) do(ctx context.Context, op *Operation) (*http.Response, io.ReadCloser, error) {
+	query := op.query
+	if c.InjectTypename {
+		var err error
+		if query, err = op.typenameQuery(); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	reqData := struct {
 		Query string                 `json:"query"`
 		Vars  map[string]interface{} `json:"variables"`
 	}{
-		Query: op.query,
+		Query: query,
 		Vars:  op.vars,
 	}
 
 	var reqBuf bytes.Buffer
 	if err := json.NewEncoder(&reqBuf).Encode(&reqData); err != nil {
-		return fmt.Errorf("failed to encode request payload: %v", err)
+		return nil, nil, fmt.Errorf("failed to encode request payload: %v", err)
 	}
 
+	var closers multiCloser
+	defer func() {
+		if closers != nil {
+			closers.Close()
+		}
+	}()
+
 	var reqBody io.Reader
-	var contentType string
+	var contentType, contentEncoding string
 	if len(op.uploads) > 0 {
 		pr, pw := io.Pipe()
-		defer pr.Close()
+		closers = append(closers, pr)
 
 		reqBody = pr
 		contentType = writeMultipart(pw, op.uploads, &reqBuf)
 	} else {
-		reqBody = &reqBuf
 		contentType = "application/json; charset=utf-8"
+		if c.RequestEncoding != nil {
+			var compressed bytes.Buffer
+			if err := compressBody(c.RequestEncoding, &compressed, &reqBuf); err != nil {
+				return nil, nil, fmt.Errorf("failed to compress request payload: %v", err)
+			}
+			reqBuf = compressed
+			contentEncoding = c.RequestEncoding.Name
+		}
 	}
 
-	// io.TeeReader(reqBody, os.Stderr)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, reqBody)
-	if err != nil {
-		return fmt.Errorf("failed to create HTTP request: %v", err)
-	}
-	req.Header.Set("Content-Type", contentType)
-	req.Header.Set("Accept", "application/json")
+	retry := reqBody == nil && c.endpoints.len() > 1 && isIdempotent(query)
 
-	resp, err := c.http.Do(req)
+	var resp *http.Response
+	var err error
+	tried := make(map[*endpoint]bool)
+	for {
+		ep := c.endpoints.pick(tried)
+		tried[ep] = true
+
+		body := reqBody
+		if body == nil {
+			body = bytes.NewReader(reqBuf.Bytes())
+		}
+
+		start := time.Now()
+		resp, err = c.send(ctx, ep.url, body, contentType, contentEncoding)
+		failed := err != nil || resp.StatusCode/100 == 5
+		// Failures caused by the caller giving up don't tell anything about
+		// the endpoint's health
+		if ctx.Err() == nil {
+			c.endpoints.report(ep, time.Since(start), failed)
+		}
+
+		if !failed || !retry || len(tried) >= c.endpoints.len() || ctx.Err() != nil {
+			break
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+	}
 	if err != nil {
-		return fmt.Errorf("HTTP request failed: %v", err)
+		return nil, nil, err
 	}
-	defer resp.Body.Close()
+	closers = append(closers, resp.Body)
 
 	contentType = resp.Header.Get("Content-Type")
 	if contentType == "" {
@@ -128,28 +242,67 @@ func (c *Client) Execute(ctx context.Context, op *Operation, data interface{}) e
 	}
 	mediaType, _, err := mime.ParseMediaType(contentType)
 	if err != nil {
-		return fmt.Errorf("invalid Content-Type %q: %v", contentType, err)
+		return nil, nil, fmt.Errorf("invalid Content-Type %q: %v", contentType, err)
 	} else if mediaType != "application/json" {
 		if resp.StatusCode/100 != 2 {
-			return &HTTPError{
+			return nil, nil, &HTTPError{
 				StatusCode: resp.StatusCode,
 				statusText: resp.Status,
 			}
 		}
-		return fmt.Errorf("invalid Content-Type %q: expected application/json", contentType)
+		return nil, nil, fmt.Errorf("invalid Content-Type %q: expected application/json", contentType)
+	}
+
+	var respBody io.Reader = resp.Body
+	if len(c.ResponseEncodings) > 0 {
+		rc, err := Decompress(c.ResponseEncodings, resp.Header.Get("Content-Encoding"), resp.Body)
+		if err != nil {
+			return nil, nil, err
+		}
+		closers = append(closers, rc)
+		respBody = rc
+	}
+
+	body := &responseBody{Reader: c.limitBody(respBody), closers: closers}
+	closers = nil
+	return resp, body, nil
+}
+
+func (c *Client) send(ctx context.Context, endpoint string, body io.Reader, contentType, contentEncoding string) (*http.Response, error) {
+	// io.TeeReader(body, os.Stderr)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+	if contentEncoding != "" {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
+	if len(c.ResponseEncodings) > 0 {
+		req.Header.Set("Accept-Encoding", acceptEncoding(c.ResponseEncodings))
 	}
 
-	respData := struct {
-		Data   interface{}
-		Errors []Error
-	}{Data: data}
-	// io.TeeReader(resp.Body, os.Stderr)
-	if err := json.NewDecoder(resp.Body).Decode(&respData); err != nil {
-		return fmt.Errorf("failed to decode response payload: %v", err)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %v", err)
 	}
+	return resp, nil
+}
 
-	if len(respData.Errors) > 0 {
-		err = joinErrors(respData.Errors)
+// decodeError wraps an error which occurred while decoding a response body.
+func decodeError(err error) error {
+	if limitErr := asLimitError(err); limitErr != nil {
+		return limitErr
+	}
+	return fmt.Errorf("failed to decode response payload: %v", err)
+}
+
+// responseError builds the error returned for a GraphQL response.
+func responseError(resp *http.Response, errs []Error) error {
+	var err error
+	if len(errs) > 0 {
+		err = joinErrors(errs)
 	}
 	if resp.StatusCode/100 != 2 {
 		err = &HTTPError{
@@ -160,3 +313,24 @@ func (c *Client) Execute(ctx context.Context, op *Operation, data interface{}) e
 	}
 	return err
 }
+
+type multiCloser []io.Closer
+
+func (l multiCloser) Close() error {
+	var err error
+	for i := len(l) - 1; i >= 0; i-- {
+		if closeErr := l[i].Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+type responseBody struct {
+	io.Reader
+	closers multiCloser
+}
+
+func (body *responseBody) Close() error {
+	return body.closers.Close()
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"github.com/dave/jennifer/jen"
+)
+
+// opFunc describes the signature of a generated operation function, without
+// the client and context parameters and the error result.
+type opFunc struct {
+	name    string
+	params  []opParam
+	results []opParam
+}
+
+type opParam struct {
+	name string
+	typ  jen.Code
+}
+
+// genClient generates an interface listing the operation functions, a
+// default implementation wrapping a *gqlclient.Client and a mock
+// implementation with stubs and call recording.
+func genClient(name string, funcs []*opFunc) *jen.Statement {
+	implName := "default" + name
+	mockName := "Mock" + name
+
+	var methods, mockFields []jen.Code
+	var impl, mock []jen.Code
+	callNames := make(map[*opFunc]string)
+	for _, fn := range funcs {
+		var in, inTypes, args, callFields, callValues []jen.Code
+		in = append(in, jen.Id("ctx").Qual("context", "Context"))
+		inTypes = append(inTypes, jen.Qual("context", "Context"))
+		args = append(args, jen.Id("ctx"))
+		for _, p := range fn.params {
+			in = append(in, jen.Id(p.name).Add(p.typ))
+			inTypes = append(inTypes, p.typ)
+			args = append(args, jen.Id(p.name))
+			callFields = append(callFields, jen.Id(goName(p.name)).Add(p.typ))
+			callValues = append(callValues, jen.Id(goName(p.name)).Op(":").Id(p.name))
+		}
+
+		var out, outTypes []jen.Code
+		for _, p := range fn.results {
+			out = append(out, jen.Id(p.name).Add(p.typ))
+			outTypes = append(outTypes, p.typ)
+		}
+		out = append(out, jen.Id("err").Error())
+		outTypes = append(outTypes, jen.Error())
+
+		methods = append(methods, jen.Id(fn.name).Params(in...).Params(out...))
+
+		impl = append(impl, jen.Func().Params(jen.Id("impl").Op("*").Id(implName)).Id(fn.name).Params(in...).Params(out...).Block(
+			jen.Return(jen.Id(fn.name).Call(append([]jen.Code{jen.Id("impl").Dot("client")}, args...)...)),
+		), jen.Line(), jen.Line())
+
+		callName := declared.add(mockName + fn.name + "Call")
+		callNames[fn] = callName
+		callsField := "calls" + fn.name
+		mockFields = append(mockFields, jen.Id(fn.name+"Func").Func().Params(inTypes...).Params(outTypes...))
+
+		mock = append(mock,
+			jen.Comment(callName+" records a call to "+mockName+"."+fn.name+".").Line(),
+			jen.Type().Id(callName).Struct(callFields...),
+			jen.Line(), jen.Line(),
+			jen.Func().Params(jen.Id("mock").Op("*").Id(mockName)).Id(fn.name).Params(in...).Params(out...).Block(
+				jen.Id("mock").Dot("mutex").Dot("Lock").Call(),
+				jen.Id("mock").Dot(callsField).Op("=").Append(jen.Id("mock").Dot(callsField), jen.Id(callName).Values(callValues...)),
+				jen.Id("mock").Dot("mutex").Dot("Unlock").Call(),
+				jen.Line(),
+				jen.If(jen.Id("mock").Dot(fn.name+"Func").Op("==").Nil()).Block(
+					jen.Panic(jen.Lit(mockName+": "+fn.name+" called but "+fn.name+"Func is nil")),
+				),
+				jen.Return(jen.Id("mock").Dot(fn.name+"Func").Call(args...)),
+			),
+			jen.Line(), jen.Line(),
+			jen.Comment(fn.name+"Calls returns the calls to "+fn.name+".").Line(),
+			jen.Func().Params(jen.Id("mock").Op("*").Id(mockName)).Id(fn.name+"Calls").Params().Index().Id(callName).Block(
+				jen.Id("mock").Dot("mutex").Dot("Lock").Call(),
+				jen.Defer().Id("mock").Dot("mutex").Dot("Unlock").Call(),
+				jen.Return(jen.Append(jen.Index().Id(callName).Call(jen.Nil()), jen.Id("mock").Dot(callsField).Op("..."))),
+			),
+			jen.Line(), jen.Line(),
+		)
+	}
+
+	mockFields = append(mockFields, jen.Line(), jen.Id("mutex").Qual("sync", "Mutex"))
+	for _, fn := range funcs {
+		mockFields = append(mockFields, jen.Id("calls"+fn.name).Index().Id(callNames[fn]))
+	}
+
+	stmt := jen.Comment(name + " executes GraphQL operations.").Line()
+	stmt.Type().Id(name).Interface(methods...).Line().Line()
+
+	stmt.Comment("New" + name + " creates a new " + name + " executing operations with a gqlclient.Client.").Line()
+	stmt.Func().Id("New" + name).Params(jen.Id("client").Op("*").Qual(gqlclientPath, "Client")).Id(name).Block(
+		jen.Return(jen.Op("&").Id(implName).Values(jen.Id("client"))),
+	).Line().Line()
+	stmt.Type().Id(implName).Struct(jen.Id("client").Op("*").Qual(gqlclientPath, "Client")).Line().Line()
+	stmt.Add(impl...)
+
+	stmt.Comment(mockName + " is a mock implementation of " + name + ". Operations call the").Line()
+	stmt.Comment("function field of the same name with a Func suffix, which must be set.").Line()
+	stmt.Type().Id(mockName).Struct(mockFields...).Line().Line()
+	stmt.Var().Id("_").Id(name).Op("=").Parens(jen.Op("*").Id(mockName)).Parens(jen.Nil()).Line().Line()
+	stmt.Add(mock...)
+	return stmt
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// defaultConfigFilename is the configuration file loaded when gqlclientgen is
+// invoked without a schema or configuration file.
+const defaultConfigFilename = "gqlclientgen.json"
+
+// config is the gqlclientgen configuration file. Options at the top level
+// apply to all targets.
+type config struct {
+	Scalars      map[string]string `json:"scalars"`
+	ScalarCodecs map[string]string `json:"scalarCodecs"`
+	Bindings     map[string]string `json:"bindings"`
+	TypeNames    map[string]string `json:"typeNames"`
+	Initialisms  []string          `json:"initialisms"`
+	Targets      []*target         `json:"targets"`
+}
+
+// target describes a generated Go file.
+type target struct {
+	// Glob patterns for the GraphQL schema and query documents
+	Schema  []string `json:"schema"`
+	Queries []string `json:"queries"`
+
+	Output         string `json:"output"`
+	Package        string `json:"package"`
+	OmitDeprecated bool   `json:"omitDeprecated"`
+	Omittable      bool   `json:"omittable"`
+	OperationTypes bool   `json:"operationTypes"`
+	Interface      string `json:"interface"`
+
+	// GraphQL scalar names to Go types implementing json.Marshaler and
+	// json.Unmarshaler
+	Scalars map[string]string `json:"scalars"`
+	// GraphQL scalar names to Go types encoded with the codec registered via
+	// gqlclient.RegisterScalar
+	ScalarCodecs map[string]string `json:"scalarCodecs"`
+	// GraphQL type names to existing Go types, which aren't generated
+	Bindings map[string]string `json:"bindings"`
+	// GraphQL type names to generated Go type names
+	TypeNames map[string]string `json:"typeNames"`
+	// Initialisms written in upper case in generated identifiers, in
+	// addition to the default ones
+	Initialisms []string `json:"initialisms"`
+}
+
+// loadConfig loads a configuration file. Relative paths are resolved against
+// the directory of the configuration file.
+func loadConfig(filename string) (*config, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// Reject unknown keys, which are most likely typos
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	var cfg config
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	// Unlike json.Unmarshal, the decoder doesn't check what follows the value
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after configuration")
+	}
+	if len(cfg.Targets) == 0 {
+		return nil, fmt.Errorf("no targets")
+	}
+
+	dir := filepath.Dir(filename)
+	for i, t := range cfg.Targets {
+		if len(t.Schema) == 0 || t.Output == "" {
+			return nil, fmt.Errorf("target #%v: schema and output are required", i)
+		}
+		for j, pattern := range t.Schema {
+			t.Schema[j] = resolvePath(dir, pattern)
+		}
+		for j, pattern := range t.Queries {
+			t.Queries[j] = resolvePath(dir, pattern)
+		}
+		t.Output = resolvePath(dir, t.Output)
+
+		t.Scalars = mergeMaps(cfg.Scalars, t.Scalars)
+		t.ScalarCodecs = mergeMaps(cfg.ScalarCodecs, t.ScalarCodecs)
+		t.Bindings = mergeMaps(cfg.Bindings, t.Bindings)
+		t.TypeNames = mergeMaps(cfg.TypeNames, t.TypeNames)
+		t.Initialisms = append(append([]string(nil), cfg.Initialisms...), t.Initialisms...)
+	}
+
+	return &cfg, nil
+}
+
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// mergeMaps returns a new map with the entries of base, overridden by the
+// entries of m.
+func mergeMaps(base, m map[string]string) map[string]string {
+	out := make(map[string]string, len(base)+len(m))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// expandGlobs returns the files matching a list of glob patterns, as
+// accepted by filepath.Glob. Patterns which don't match any file are
+// rejected.
+func expandGlobs(patterns []string) ([]string, error) {
+	var filenames []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		} else if len(matches) == 0 {
+			return nil, fmt.Errorf("no file matches %q", pattern)
+		}
+		filenames = append(filenames, matches...)
+	}
+	return filenames, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, s string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "gqlclientgen.json")
+	if err := os.WriteFile(filename, []byte(s), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return filename
+}
+
+func TestLoadConfig(t *testing.T) {
+	filename := writeConfig(t, `{
+		"scalars": {"UUID": "example.org/a.UUID", "Time": "example.org/a.Time"},
+		"typeNames": {"User": "Account"},
+		"initialisms": ["SKU"],
+		"targets": [
+			{
+				"schema": ["schema/*.graphqls"],
+				"queries": ["queries/*.graphql"],
+				"output": "a/gql.go",
+				"operationTypes": true,
+				"scalars": {"Time": "example.org/b.Time"},
+				"bindings": {"Train": "example.org/b.Train"},
+				"initialisms": ["VIN"]
+			},
+			{
+				"schema": ["/srv/schema.graphqls"],
+				"output": "b/gql.go",
+				"package": "rail",
+				"interface": "Client"
+			}
+		]
+	}`)
+	dir := filepath.Dir(filename)
+
+	cfg, err := loadConfig(filename)
+	if err != nil {
+		t.Fatalf("loadConfig() = %v", err)
+	}
+
+	want := []*target{
+		{
+			Schema:         []string{filepath.Join(dir, "schema/*.graphqls")},
+			Queries:        []string{filepath.Join(dir, "queries/*.graphql")},
+			Output:         filepath.Join(dir, "a/gql.go"),
+			OperationTypes: true,
+			Scalars:        map[string]string{"UUID": "example.org/a.UUID", "Time": "example.org/b.Time"},
+			ScalarCodecs:   map[string]string{},
+			Bindings:       map[string]string{"Train": "example.org/b.Train"},
+			TypeNames:      map[string]string{"User": "Account"},
+			Initialisms:    []string{"SKU", "VIN"},
+		},
+		{
+			Schema:       []string{"/srv/schema.graphqls"},
+			Output:       filepath.Join(dir, "b/gql.go"),
+			Package:      "rail",
+			Interface:    "Client",
+			Scalars:      map[string]string{"UUID": "example.org/a.UUID", "Time": "example.org/a.Time"},
+			ScalarCodecs: map[string]string{},
+			Bindings:     map[string]string{},
+			TypeNames:    map[string]string{"User": "Account"},
+			Initialisms:  []string{"SKU"},
+		},
+	}
+	if len(cfg.Targets) != len(want) {
+		t.Fatalf("loadConfig() = %v targets, want %v", len(cfg.Targets), len(want))
+	}
+	for i, got := range cfg.Targets {
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("target #%v = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{name: "no targets", config: `{}`, want: "no targets"},
+		{name: "missing output", config: `{"targets": [{"schema": ["schema.graphqls"]}]}`, want: "target #0: schema and output are required"},
+		{name: "unknown key", config: `{"scalar": {}, "targets": []}`, want: `unknown field "scalar"`},
+		{name: "unknown target key", config: `{"targets": [{"schemas": ["schema.graphqls"]}]}`, want: `unknown field "schemas"`},
+		{name: "trailing data", config: `{"targets": [{"schema": ["schema.graphqls"], "output": "gql.go"}]} {}`, want: "unexpected data after configuration"},
+		{name: "invalid JSON", config: `{"targets": [}`, want: "invalid character"},
+	}
+	for _, tc := range tests {
+		_, err := loadConfig(writeConfig(t, tc.config))
+		if err == nil {
+			t.Errorf("%v: loadConfig() succeeded", tc.name)
+		} else if !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: loadConfig() = %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,398 @@
+// Code generated by gqlclientgen - DO NOT EDIT.
+
+package basic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	gqlclient "git.sr.ht/~emersion/gqlclient"
+	"sync"
+)
+
+type APIKey struct {
+	ID string `json:"id"`
+}
+
+type Account struct {
+	ID  string `json:"id"`
+	Sku string `json:"sku"`
+}
+
+// Conflicts with APIKey once converted to a Go name
+type APIKey_ struct {
+	ID  string `json:"id"`
+	Key string `json:"key"`
+}
+
+type Bot struct {
+	ID     string `json:"id"`
+	Owner  *User  `json:"owner"`
+	Status Status `json:"status"`
+}
+
+func (*Bot) isNode() {}
+
+func (*Bot) isSearchResult() {}
+
+// Hex color, e.g. #ff0000
+type Color string
+
+// Opaque pagination cursor
+type Cursor string
+
+// An object with a globally unique ID
+type Node struct {
+	ID string `json:"id"`
+
+	// Underlying value of the GraphQL interface
+	Value NodeValue `json:"-"`
+}
+
+func (base *Node) UnmarshalJSON(b []byte) error {
+	type Raw Node
+	var data struct {
+		*Raw
+		TypeName string `json:"__typename"`
+	}
+	data.Raw = (*Raw)(base)
+	err := json.Unmarshal(b, &data)
+	if err != nil {
+		return err
+	}
+	switch data.TypeName {
+	case "User":
+		base.Value = new(User)
+	case "Bot":
+		base.Value = new(Bot)
+	case "":
+		return nil
+	default:
+		return fmt.Errorf("gqlclient: interface Node: unknown __typename %q", data.TypeName)
+	}
+	return json.Unmarshal(b, base.Value)
+}
+
+// NodeValue is one of: User | Bot
+type NodeValue interface {
+	isNode()
+}
+
+type SearchResult struct {
+	// Underlying value of the GraphQL union
+	Value SearchResultValue `json:"-"`
+}
+
+func (base *SearchResult) UnmarshalJSON(b []byte) error {
+	type Raw SearchResult
+	var data struct {
+		*Raw
+		TypeName string `json:"__typename"`
+	}
+	data.Raw = (*Raw)(base)
+	err := json.Unmarshal(b, &data)
+	if err != nil {
+		return err
+	}
+	switch data.TypeName {
+	case "User":
+		base.Value = new(User)
+	case "Bot":
+		base.Value = new(Bot)
+	case "":
+		return fmt.Errorf("gqlclient: union SearchResult: missing __typename field")
+	default:
+		return fmt.Errorf("gqlclient: union SearchResult: unknown __typename %q", data.TypeName)
+	}
+	return json.Unmarshal(b, base.Value)
+}
+
+// SearchResultValue is one of: User | Bot
+type SearchResultValue interface {
+	isSearchResult()
+}
+
+type Status string
+
+const (
+	StatusActive_   Status = "ACTIVE"
+	StatusHTTPError Status = "HTTP_ERROR"
+)
+
+// Conflicts with the constant generated for Status.ACTIVE
+type StatusActive struct {
+	Since gqlclient.Time `json:"since"`
+}
+
+// Absolute URL
+type URL string
+
+type User struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// URL of the user's profile
+	APIURL        *URL            `json:"apiUrl,omitempty"`
+	ImageURLs     []string        `json:"imageURLs"`
+	CreatedAt     gqlclient.Time  `json:"createdAt"`
+	UUID          *gqlclient.UUID `json:"uuid,omitempty"`
+	Status        *Status         `json:"status"`
+	Type          *string         `json:"type,omitempty"`
+	X1st          *bool           `json:"_1st,omitempty"`
+	FavoriteColor *Color          `json:"favoriteColor,omitempty"`
+	Friends       []User          `json:"friends"`
+	Account       *Account        `json:"account,omitempty"`
+}
+
+func (*User) isNode() {}
+
+func (*User) isSearchResult() {}
+
+type UserInput struct {
+	ID            string                      `json:"id"`
+	Name          gqlclient.Omittable[string] `json:"name"`
+	Status        gqlclient.Omittable[Status] `json:"status"`
+	FavoriteColor gqlclient.Omittable[Color]  `json:"favoriteColor"`
+	APIURL        gqlclient.Omittable[URL]    `json:"apiUrl"`
+}
+
+func (v UserInput) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{})
+	m["id"] = v.ID
+	if !v.Name.IsOmitted() {
+		m["name"] = v.Name
+	}
+	if !v.Status.IsOmitted() {
+		m["status"] = v.Status
+	}
+	if !v.FavoriteColor.IsOmitted() {
+		m["favoriteColor"] = v.FavoriteColor
+	}
+	if !v.APIURL.IsOmitted() {
+		m["apiUrl"] = v.APIURL
+	}
+	return json.Marshal(m)
+}
+
+func FetchMe(client *gqlclient.Client, ctx context.Context, range_ *int32) (me *User, err error) {
+	op := gqlclient.NewOperation("query fetchMe ($range: Int) {\n\tme {\n\t\t... UserFields\n\t\taccount {\n\t\t\tid\n\t\t\tsku\n\t\t}\n\t\tfriends(first: $range) {\n\t\t\tid\n\t\t\tname\n\t\t}\n\t}\n}\nfragment UserFields on User {\n\tid\n\tname\n\tapiUrl\n\timageURLs\n\tcreatedAt\n\tuuid\n\tfavoriteColor\n\t_1st\n\ttype\n}\n")
+	op.Var("range", range_)
+	var respData struct {
+		Me *User `json:"me"`
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.Me, err
+}
+
+func FetchUser(client *gqlclient.Client, ctx context.Context, id string, withFriends bool) (user *User, type_ *User, err error) {
+	op := gqlclient.NewOperation("query fetchUser ($id: ID!, $withFriends: Boolean!) {\n\tuser(id: $id) {\n\t\t... UserFields\n\t\tstatus @skip(if: $withFriends)\n\t\tfriends @include(if: $withFriends) {\n\t\t\tid\n\t\t}\n\t}\n\ttype: user(id: $id) {\n\t\tid\n\t}\n}\nfragment UserFields on User {\n\tid\n\tname\n\tapiUrl\n\timageURLs\n\tcreatedAt\n\tuuid\n\tfavoriteColor\n\t_1st\n\ttype\n}\n")
+	op.Var("id", id)
+	op.Var("withFriends", withFriends)
+	var respData struct {
+		User *User `json:"user"`
+		Type *User `json:"type"`
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.User, respData.Type, err
+}
+
+func Search(client *gqlclient.Client, ctx context.Context, query string, after *Cursor) (search []SearchResult, err error) {
+	op := gqlclient.NewOperation("query search ($query: String!, $after: Cursor) {\n\tsearch(query: $query, after: $after) {\n\t\t__typename\n\t\t... on User {\n\t\t\tid\n\t\t\tname\n\t\t}\n\t\t... on Bot {\n\t\t\tid\n\t\t\towner {\n\t\t\t\tid\n\t\t\t}\n\t\t}\n\t}\n}\n")
+	op.Var("query", query)
+	op.Var("after", after)
+	var respData struct {
+		Search []SearchResult `json:"search"`
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.Search, err
+}
+
+func FetchAPIKeys(client *gqlclient.Client, ctx context.Context) (apiKeys []APIKey_, err error) {
+	op := gqlclient.NewOperation("query fetchAPIKeys {\n\tapiKeys {\n\t\tid\n\t\tkey\n\t}\n}\n")
+	var respData struct {
+		APIKeys []APIKey_ `json:"apiKeys"`
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.APIKeys, err
+}
+
+func UpdateUser(client *gqlclient.Client, ctx context.Context, input UserInput) (updateUser *User, err error) {
+	op := gqlclient.NewOperation("mutation updateUser ($input: UserInput!) {\n\tupdateUser(input: $input) {\n\t\tid\n\t\tname\n\t}\n}\n")
+	op.Var("input", input)
+	var respData struct {
+		UpdateUser *User `json:"updateUser"`
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.UpdateUser, err
+}
+
+// Client executes GraphQL operations.
+type Client interface {
+	FetchMe(ctx context.Context, range_ *int32) (me *User, err error)
+	FetchUser(ctx context.Context, id string, withFriends bool) (user *User, type_ *User, err error)
+	Search(ctx context.Context, query string, after *Cursor) (search []SearchResult, err error)
+	FetchAPIKeys(ctx context.Context) (apiKeys []APIKey_, err error)
+	UpdateUser(ctx context.Context, input UserInput) (updateUser *User, err error)
+}
+
+// NewClient creates a new Client executing operations with a gqlclient.Client.
+func NewClient(client *gqlclient.Client) Client {
+	return &defaultClient{client}
+}
+
+type defaultClient struct {
+	client *gqlclient.Client
+}
+
+func (impl *defaultClient) FetchMe(ctx context.Context, range_ *int32) (me *User, err error) {
+	return FetchMe(impl.client, ctx, range_)
+}
+
+func (impl *defaultClient) FetchUser(ctx context.Context, id string, withFriends bool) (user *User, type_ *User, err error) {
+	return FetchUser(impl.client, ctx, id, withFriends)
+}
+
+func (impl *defaultClient) Search(ctx context.Context, query string, after *Cursor) (search []SearchResult, err error) {
+	return Search(impl.client, ctx, query, after)
+}
+
+func (impl *defaultClient) FetchAPIKeys(ctx context.Context) (apiKeys []APIKey_, err error) {
+	return FetchAPIKeys(impl.client, ctx)
+}
+
+func (impl *defaultClient) UpdateUser(ctx context.Context, input UserInput) (updateUser *User, err error) {
+	return UpdateUser(impl.client, ctx, input)
+}
+
+// MockClient is a mock implementation of Client. Operations call the
+// function field of the same name with a Func suffix, which must be set.
+type MockClient struct {
+	FetchMeFunc      func(context.Context, *int32) (*User, error)
+	FetchUserFunc    func(context.Context, string, bool) (*User, *User, error)
+	SearchFunc       func(context.Context, string, *Cursor) ([]SearchResult, error)
+	FetchAPIKeysFunc func(context.Context) ([]APIKey_, error)
+	UpdateUserFunc   func(context.Context, UserInput) (*User, error)
+
+	mutex             sync.Mutex
+	callsFetchMe      []MockClientFetchMeCall
+	callsFetchUser    []MockClientFetchUserCall
+	callsSearch       []MockClientSearchCall
+	callsFetchAPIKeys []MockClientFetchAPIKeysCall
+	callsUpdateUser   []MockClientUpdateUserCall
+}
+
+var _ Client = (*MockClient)(nil)
+
+// MockClientFetchMeCall records a call to MockClient.FetchMe.
+type MockClientFetchMeCall struct {
+	Range *int32
+}
+
+func (mock *MockClient) FetchMe(ctx context.Context, range_ *int32) (me *User, err error) {
+	mock.mutex.Lock()
+	mock.callsFetchMe = append(mock.callsFetchMe, MockClientFetchMeCall{Range: range_})
+	mock.mutex.Unlock()
+
+	if mock.FetchMeFunc == nil {
+		panic("MockClient: FetchMe called but FetchMeFunc is nil")
+	}
+	return mock.FetchMeFunc(ctx, range_)
+}
+
+// FetchMeCalls returns the calls to FetchMe.
+func (mock *MockClient) FetchMeCalls() []MockClientFetchMeCall {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	return append([]MockClientFetchMeCall(nil), mock.callsFetchMe...)
+}
+
+// MockClientFetchUserCall records a call to MockClient.FetchUser.
+type MockClientFetchUserCall struct {
+	ID          string
+	WithFriends bool
+}
+
+func (mock *MockClient) FetchUser(ctx context.Context, id string, withFriends bool) (user *User, type_ *User, err error) {
+	mock.mutex.Lock()
+	mock.callsFetchUser = append(mock.callsFetchUser, MockClientFetchUserCall{ID: id, WithFriends: withFriends})
+	mock.mutex.Unlock()
+
+	if mock.FetchUserFunc == nil {
+		panic("MockClient: FetchUser called but FetchUserFunc is nil")
+	}
+	return mock.FetchUserFunc(ctx, id, withFriends)
+}
+
+// FetchUserCalls returns the calls to FetchUser.
+func (mock *MockClient) FetchUserCalls() []MockClientFetchUserCall {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	return append([]MockClientFetchUserCall(nil), mock.callsFetchUser...)
+}
+
+// MockClientSearchCall records a call to MockClient.Search.
+type MockClientSearchCall struct {
+	Query string
+	After *Cursor
+}
+
+func (mock *MockClient) Search(ctx context.Context, query string, after *Cursor) (search []SearchResult, err error) {
+	mock.mutex.Lock()
+	mock.callsSearch = append(mock.callsSearch, MockClientSearchCall{Query: query, After: after})
+	mock.mutex.Unlock()
+
+	if mock.SearchFunc == nil {
+		panic("MockClient: Search called but SearchFunc is nil")
+	}
+	return mock.SearchFunc(ctx, query, after)
+}
+
+// SearchCalls returns the calls to Search.
+func (mock *MockClient) SearchCalls() []MockClientSearchCall {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	return append([]MockClientSearchCall(nil), mock.callsSearch...)
+}
+
+// MockClientFetchAPIKeysCall records a call to MockClient.FetchAPIKeys.
+type MockClientFetchAPIKeysCall struct{}
+
+func (mock *MockClient) FetchAPIKeys(ctx context.Context) (apiKeys []APIKey_, err error) {
+	mock.mutex.Lock()
+	mock.callsFetchAPIKeys = append(mock.callsFetchAPIKeys, MockClientFetchAPIKeysCall{})
+	mock.mutex.Unlock()
+
+	if mock.FetchAPIKeysFunc == nil {
+		panic("MockClient: FetchAPIKeys called but FetchAPIKeysFunc is nil")
+	}
+	return mock.FetchAPIKeysFunc(ctx)
+}
+
+// FetchAPIKeysCalls returns the calls to FetchAPIKeys.
+func (mock *MockClient) FetchAPIKeysCalls() []MockClientFetchAPIKeysCall {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	return append([]MockClientFetchAPIKeysCall(nil), mock.callsFetchAPIKeys...)
+}
+
+// MockClientUpdateUserCall records a call to MockClient.UpdateUser.
+type MockClientUpdateUserCall struct {
+	Input UserInput
+}
+
+func (mock *MockClient) UpdateUser(ctx context.Context, input UserInput) (updateUser *User, err error) {
+	mock.mutex.Lock()
+	mock.callsUpdateUser = append(mock.callsUpdateUser, MockClientUpdateUserCall{Input: input})
+	mock.mutex.Unlock()
+
+	if mock.UpdateUserFunc == nil {
+		panic("MockClient: UpdateUser called but UpdateUserFunc is nil")
+	}
+	return mock.UpdateUserFunc(ctx, input)
+}
+
+// UpdateUserCalls returns the calls to UpdateUser.
+func (mock *MockClient) UpdateUserCalls() []MockClientUpdateUserCall {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	return append([]MockClientUpdateUserCall(nil), mock.callsUpdateUser...)
+}
@@ -0,0 +1,160 @@
+package basic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"git.sr.ht/~emersion/gqlclient"
+)
+
+func newTestClient(t *testing.T, body string) *gqlclient.Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return gqlclient.New(srv.URL, nil)
+}
+
+func TestFetchUser(t *testing.T) {
+	c := newTestClient(t, `{"data":{
+		"user":{"id":"1","name":"emersion","imageURLs":[],"createdAt":"2006-01-02T15:04:05Z","_1st":true,"friends":[{"id":"2"}]},
+		"type":{"id":"1"}
+	}}`)
+
+	user, other, err := NewClient(c).FetchUser(context.Background(), "1", true)
+	if err != nil {
+		t.Fatalf("FetchUser() = %v", err)
+	}
+	if user.ID != "1" || user.Name != "emersion" || other.ID != "1" {
+		t.Errorf("FetchUser() = %+v, %+v", user, other)
+	}
+	if user.Status != nil {
+		t.Errorf("skipped status = %v, want nil", *user.Status)
+	}
+	if user.X1st == nil || !*user.X1st {
+		t.Errorf("_1st = %v, want true", user.X1st)
+	}
+	if len(user.Friends) != 1 || user.Friends[0].ID != "2" {
+		t.Errorf("friends = %+v", user.Friends)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	c := newTestClient(t, `{"data":{"search":[
+		{"__typename":"User","id":"1","name":"emersion"},
+		{"__typename":"Bot","id":"2","owner":{"id":"1"}}
+	]}}`)
+
+	results, err := Search(c, context.Background(), "e", nil)
+	if err != nil {
+		t.Fatalf("Search() = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Search() = %v results, want 2", len(results))
+	}
+	if user, ok := results[0].Value.(*User); !ok || user.Name != "emersion" {
+		t.Errorf("results[0] = %#v, want a user", results[0].Value)
+	}
+	if bot, ok := results[1].Value.(*Bot); !ok || bot.Owner.ID != "1" {
+		t.Errorf("results[1] = %#v, want a bot", results[1].Value)
+	}
+}
+
+func TestMockClient(t *testing.T) {
+	mock := &MockClient{
+		FetchMeFunc: func(ctx context.Context, n *int32) (*User, error) {
+			return &User{ID: "1", Friends: make([]User, *n)}, nil
+		},
+	}
+
+	var c Client = mock
+	n := int32(2)
+	me, err := c.FetchMe(context.Background(), &n)
+	if err != nil {
+		t.Fatalf("FetchMe() = %v", err)
+	}
+	if me.ID != "1" || len(me.Friends) != 2 {
+		t.Errorf("FetchMe() = %+v", me)
+	}
+
+	calls := mock.FetchMeCalls()
+	if len(calls) != 1 || calls[0].Range != &n {
+		t.Errorf("FetchMeCalls() = %+v", calls)
+	}
+	if len(mock.SearchCalls()) != 0 {
+		t.Errorf("SearchCalls() = %+v, want none", mock.SearchCalls())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Search() without SearchFunc didn't panic")
+		}
+	}()
+	c.Search(context.Background(), "e", nil)
+}
+
+func TestUserInputMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input UserInput
+		want  string
+	}{
+		{
+			name:  "omitted",
+			input: UserInput{ID: "1"},
+			want:  `{"id":"1"}`,
+		},
+		{
+			name:  "null",
+			input: UserInput{ID: "1", Name: gqlclient.OmittableNull[string]()},
+			want:  `{"id":"1","name":null}`,
+		},
+		{
+			name: "values",
+			input: UserInput{
+				ID:     "1",
+				Name:   gqlclient.OmittableValue(""),
+				Status: gqlclient.OmittableValue(StatusHTTPError),
+				APIURL: gqlclient.OmittableNull[URL](),
+			},
+			want: `{"apiUrl":null,"id":"1","name":"","status":"HTTP_ERROR"}`,
+		},
+	}
+	for _, tc := range tests {
+		b, err := json.Marshal(tc.input)
+		if err != nil {
+			t.Errorf("%v: Marshal() = %v", tc.name, err)
+		} else if string(b) != tc.want {
+			t.Errorf("%v: Marshal() = %s, want %s", tc.name, b, tc.want)
+		}
+	}
+
+	// Variables are marshaled with the generated method
+	var reqData struct {
+		Variables map[string]map[string]interface{}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := json.NewDecoder(req.Body).Decode(&reqData); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"updateUser":{"id":"1","name":"","imageURLs":[],"createdAt":null,"friends":[]}}}`))
+	}))
+	defer srv.Close()
+
+	input := UserInput{ID: "1", Name: gqlclient.OmittableNull[string]()}
+	if _, err := UpdateUser(gqlclient.New(srv.URL, nil), context.Background(), input); err != nil {
+		t.Fatalf("UpdateUser() = %v", err)
+	}
+	vars := reqData.Variables["input"]
+	if name, ok := vars["name"]; !ok || name != nil {
+		t.Errorf("name = %v, want an explicit null", name)
+	}
+	if _, ok := vars["status"]; ok {
+		t.Errorf("omitted status sent: %v", vars)
+	}
+}
@@ -0,0 +1,100 @@
+package bindings
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"git.sr.ht/~emersion/gqlclient"
+	"git.sr.ht/~emersion/gqlclient/cmd/gqlclientgen/internal/golden/shared"
+)
+
+func init() {
+	gqlclient.RegisterScalar("URL", &gqlclient.Scalar{
+		GoType: "net/url.URL",
+		Marshal: func(v interface{}) ([]byte, error) {
+			return json.Marshal(v.(*url.URL).String())
+		},
+		Unmarshal: func(b []byte, v interface{}) error {
+			var s string
+			if err := json.Unmarshal(b, &s); err != nil {
+				return err
+			}
+			u, err := url.Parse(s)
+			if err != nil {
+				return err
+			}
+			*v.(*url.URL) = *u
+			return nil
+		},
+	})
+}
+
+func newTestClient(t *testing.T, body string) *gqlclient.Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return gqlclient.New(srv.URL, nil)
+}
+
+func TestFetchMe(t *testing.T) {
+	c := newTestClient(t, `{"data":{"me":{
+		"id":"1","name":"emersion","imageURLs":[],"createdAt":"2006-01-02T15:04:05Z",
+		"apiUrl":"https://example.org/~emersion","favoriteColor":"#ff8000",
+		"account":{"id":"2","sku":"x"},"friends":[]
+	}}}`)
+
+	me, err := FetchMe(c, context.Background(), nil)
+	if err != nil {
+		t.Fatalf("FetchMe() = %v", err)
+	}
+	if me.APIURL == nil || me.APIURL.Value.Host != "example.org" {
+		t.Errorf("apiUrl = %+v", me.APIURL)
+	}
+	if me.FavoriteColor == nil || *me.FavoriteColor != (shared.Color{R: 0xff, G: 0x80}) {
+		t.Errorf("favoriteColor = %+v", me.FavoriteColor)
+	}
+	if me.Account == nil || me.Account.SKU != "x" {
+		t.Errorf("account = %+v", me.Account)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	c := newTestClient(t, `{"data":{"search":[{"__typename":"Bot","id":"2","owner":{"id":"1"}}]}}`)
+
+	cursor := "abc"
+	results, err := Search(c, context.Background(), "e", &cursor)
+	if err != nil {
+		t.Fatalf("Search() = %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Search() = %v results, want 1", len(results))
+	}
+	if bot, ok := results[0].Value.(*shared.Bot); !ok || bot.ID != "2" {
+		t.Errorf("results[0] = %#v, want a bound bot", results[0].Value)
+	}
+}
+
+func TestUserInputMarshalJSON(t *testing.T) {
+	u, err := url.Parse("https://example.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(UserInput{
+		ID:            "1",
+		FavoriteColor: &shared.Color{B: 0xff},
+		APIURL:        &URL{*u},
+	})
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	want := `{"id":"1","favoriteColor":"#0000ff","apiUrl":"https://example.org"}`
+	if string(b) != want {
+		t.Errorf("Marshal() = %s, want %s", b, want)
+	}
+}
@@ -0,0 +1,507 @@
+// Code generated by gqlclientgen - DO NOT EDIT.
+
+package optypes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	gqlclient "git.sr.ht/~emersion/gqlclient"
+)
+
+type APIKey struct {
+	ID string `json:"id"`
+}
+
+type Account struct {
+	ID  string `json:"id"`
+	Sku string `json:"sku"`
+}
+
+// Conflicts with APIKey once converted to a Go name
+type APIKey_ struct {
+	ID  string `json:"id"`
+	Key string `json:"key"`
+}
+
+type Bot struct {
+	ID     string `json:"id"`
+	Owner  *User  `json:"owner"`
+	Status Status `json:"status"`
+}
+
+func (*Bot) isNode() {}
+
+func (*Bot) isSearchResult() {}
+
+// Hex color, e.g. #ff0000
+type Color string
+
+// Opaque pagination cursor
+type Cursor string
+
+// An object with a globally unique ID
+type Node struct {
+	ID string `json:"id"`
+
+	// Underlying value of the GraphQL interface
+	Value NodeValue `json:"-"`
+}
+
+func (base *Node) UnmarshalJSON(b []byte) error {
+	type Raw Node
+	var data struct {
+		*Raw
+		TypeName string `json:"__typename"`
+	}
+	data.Raw = (*Raw)(base)
+	err := json.Unmarshal(b, &data)
+	if err != nil {
+		return err
+	}
+	switch data.TypeName {
+	case "User":
+		base.Value = new(User)
+	case "Bot":
+		base.Value = new(Bot)
+	case "":
+		return nil
+	default:
+		return fmt.Errorf("gqlclient: interface Node: unknown __typename %q", data.TypeName)
+	}
+	return json.Unmarshal(b, base.Value)
+}
+
+// NodeValue is one of: User | Bot
+type NodeValue interface {
+	isNode()
+}
+
+type SearchResult struct {
+	// Underlying value of the GraphQL union
+	Value SearchResultValue `json:"-"`
+}
+
+func (base *SearchResult) UnmarshalJSON(b []byte) error {
+	type Raw SearchResult
+	var data struct {
+		*Raw
+		TypeName string `json:"__typename"`
+	}
+	data.Raw = (*Raw)(base)
+	err := json.Unmarshal(b, &data)
+	if err != nil {
+		return err
+	}
+	switch data.TypeName {
+	case "User":
+		base.Value = new(User)
+	case "Bot":
+		base.Value = new(Bot)
+	case "":
+		return fmt.Errorf("gqlclient: union SearchResult: missing __typename field")
+	default:
+		return fmt.Errorf("gqlclient: union SearchResult: unknown __typename %q", data.TypeName)
+	}
+	return json.Unmarshal(b, base.Value)
+}
+
+// SearchResultValue is one of: User | Bot
+type SearchResultValue interface {
+	isSearchResult()
+}
+
+type Status string
+
+const (
+	StatusActive_   Status = "ACTIVE"
+	StatusHTTPError Status = "HTTP_ERROR"
+	// Former status
+	StatusSuspended Status = "SUSPENDED"
+)
+
+// Conflicts with the constant generated for Status.ACTIVE
+type StatusActive struct {
+	Since gqlclient.Time `json:"since"`
+}
+
+// Absolute URL
+type URL string
+
+type User struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// URL of the user's profile
+	APIURL        *URL            `json:"apiUrl,omitempty"`
+	ImageURLs     []string        `json:"imageURLs"`
+	CreatedAt     gqlclient.Time  `json:"createdAt"`
+	UUID          *gqlclient.UUID `json:"uuid,omitempty"`
+	Status        Status          `json:"status"`
+	Type          *string         `json:"type,omitempty"`
+	X1st          *bool           `json:"_1st,omitempty"`
+	FavoriteColor *Color          `json:"favoriteColor,omitempty"`
+	Friends       []User          `json:"friends"`
+	Account       *Account        `json:"account,omitempty"`
+	Login         *string         `json:"login,omitempty"`
+}
+
+func (*User) isNode() {}
+
+func (*User) isSearchResult() {}
+
+type UserInput struct {
+	ID            string  `json:"id"`
+	Name          *string `json:"name,omitempty"`
+	Status        *Status `json:"status,omitempty"`
+	FavoriteColor *Color  `json:"favoriteColor,omitempty"`
+	APIURL        *URL    `json:"apiUrl,omitempty"`
+}
+
+type FetchNodeNode struct {
+	NodeID `json:"-"`
+
+	// Underlying value, depending on the concrete type
+	Value FetchNodeNodeValue `json:"-"`
+}
+
+func (v *FetchNodeNode) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &v.NodeID); err != nil {
+		return err
+	}
+	var data struct {
+		TypeName string `json:"__typename"`
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	switch data.TypeName {
+	case "User":
+		v.Value = new(FetchNodeNodeUser)
+	case "Bot":
+		v.Value = new(FetchNodeNodeBot)
+	default:
+		return fmt.Errorf("gqlclient: FetchNodeNode: unknown __typename %q", data.TypeName)
+	}
+	return json.Unmarshal(b, v.Value)
+}
+
+// FetchNodeNodeValue is one of: *FetchNodeNodeUser | *FetchNodeNodeBot
+type FetchNodeNodeValue interface {
+	isFetchNodeNode()
+}
+
+func (*FetchNodeNodeUser) isFetchNodeNode() {}
+
+func (*FetchNodeNodeBot) isFetchNodeNode() {}
+
+type NodeID struct {
+	ID string `json:"id"`
+}
+
+type FetchNodeNodeUser struct {
+	NodeID `json:"-"`
+
+	CreatedAt *gqlclient.Time `json:"createdAt"`
+	Status    *Status         `json:"status"`
+}
+
+func (v *FetchNodeNodeUser) UnmarshalJSON(b []byte) error {
+	var fields struct {
+		CreatedAt **gqlclient.Time `json:"createdAt"`
+		Status    **Status         `json:"status"`
+	}
+	fields.CreatedAt = &v.CreatedAt
+	fields.Status = &v.Status
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &v.NodeID); err != nil {
+		return err
+	}
+	return nil
+}
+
+type FetchNodeNodeBot struct {
+	NodeID    `json:"-"`
+	BotFields `json:"-"`
+}
+
+func (v *FetchNodeNodeBot) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &v.NodeID); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &v.BotFields); err != nil {
+		return err
+	}
+	return nil
+}
+
+type BotFields struct {
+	Owner BotFieldsOwner `json:"owner"`
+}
+
+type BotFieldsOwner struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+}
+
+func FetchNode(client *gqlclient.Client, ctx context.Context, id string, full bool) (node *FetchNodeNode, err error) {
+	op := gqlclient.NewOperation("query fetchNode ($id: ID!, $full: Boolean!) {\n\tnode(id: $id) {\n\t\t__typename\n\t\t... NodeID\n\t\t... BotFields\n\t\t... on User @include(if: $full) {\n\t\t\tcreatedAt\n\t\t\tstatus\n\t\t}\n\t}\n}\nfragment BotFields on Bot {\n\towner {\n\t\tid\n\t\tdisplayName: name\n\t}\n}\nfragment NodeID on Node {\n\t__typename\n\tid\n}\n")
+	op.Var("id", id)
+	op.Var("full", full)
+	var respData struct {
+		Node *FetchNodeNode `json:"node"`
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.Node, err
+}
+
+type SearchNodesSearch struct {
+	Typename string `json:"__typename"`
+	ID       string `json:"id"`
+
+	// Underlying value, depending on the concrete type
+	Value SearchNodesSearchValue `json:"-"`
+}
+
+func (v *SearchNodesSearch) UnmarshalJSON(b []byte) error {
+	var fields struct {
+		Typename *string `json:"__typename"`
+		ID       *string `json:"id"`
+	}
+	fields.Typename = &v.Typename
+	fields.ID = &v.ID
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	var data struct {
+		TypeName string `json:"__typename"`
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	switch data.TypeName {
+	case "User":
+		v.Value = new(SearchNodesSearchUser)
+	case "Bot":
+		v.Value = new(SearchNodesSearchBot)
+	default:
+		return fmt.Errorf("gqlclient: SearchNodesSearch: unknown __typename %q", data.TypeName)
+	}
+	return json.Unmarshal(b, v.Value)
+}
+
+// SearchNodesSearchValue is one of: *SearchNodesSearchUser | *SearchNodesSearchBot
+type SearchNodesSearchValue interface {
+	isSearchNodesSearch()
+}
+
+func (*SearchNodesSearchUser) isSearchNodesSearch() {}
+
+func (*SearchNodesSearchBot) isSearchNodesSearch() {}
+
+type SearchNodesSearchUser struct {
+	Typename string `json:"__typename"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+}
+
+type SearchNodesSearchBot struct {
+	Typename string `json:"__typename"`
+	ID       string `json:"id"`
+}
+
+func SearchNodes(client *gqlclient.Client, ctx context.Context, query string) (search []SearchNodesSearch, err error) {
+	op := gqlclient.NewOperation("query searchNodes ($query: String!) {\n\tsearch(query: $query) {\n\t\t__typename\n\t\t... on Node {\n\t\t\t... NodeID\n\t\t}\n\t\t... on User {\n\t\t\tname\n\t\t}\n\t}\n}\nfragment NodeID on Node {\n\t__typename\n\tid\n}\n")
+	op.Var("query", query)
+	var respData struct {
+		Search []SearchNodesSearch `json:"search"`
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.Search, err
+}
+
+type FetchMeMe struct {
+	UserFields `json:"-"`
+
+	Account *FetchMeMeAccount  `json:"account"`
+	Friends []FetchMeMeFriends `json:"friends"`
+}
+
+func (v *FetchMeMe) UnmarshalJSON(b []byte) error {
+	var fields struct {
+		Account **FetchMeMeAccount  `json:"account"`
+		Friends *[]FetchMeMeFriends `json:"friends"`
+	}
+	fields.Account = &v.Account
+	fields.Friends = &v.Friends
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &v.UserFields); err != nil {
+		return err
+	}
+	return nil
+}
+
+type UserFields struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// URL of the user's profile
+	APIURL        *URL            `json:"apiUrl"`
+	ImageURLs     []string        `json:"imageURLs"`
+	CreatedAt     gqlclient.Time  `json:"createdAt"`
+	UUID          *gqlclient.UUID `json:"uuid"`
+	FavoriteColor *Color          `json:"favoriteColor"`
+	X1st          *bool           `json:"_1st"`
+	Type          *string         `json:"type"`
+}
+
+type FetchMeMeAccount struct {
+	ID  string `json:"id"`
+	Sku string `json:"sku"`
+}
+
+type FetchMeMeFriends struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func FetchMe(client *gqlclient.Client, ctx context.Context, range_ *int32) (me FetchMeMe, err error) {
+	op := gqlclient.NewOperation("query fetchMe ($range: Int) {\n\tme {\n\t\t... UserFields\n\t\taccount {\n\t\t\tid\n\t\t\tsku\n\t\t}\n\t\tfriends(first: $range) {\n\t\t\tid\n\t\t\tname\n\t\t}\n\t}\n}\nfragment UserFields on User {\n\tid\n\tname\n\tapiUrl\n\timageURLs\n\tcreatedAt\n\tuuid\n\tfavoriteColor\n\t_1st\n\ttype\n}\n")
+	op.Var("range", range_)
+	var respData struct {
+		Me FetchMeMe `json:"me"`
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.Me, err
+}
+
+type FetchUserUser struct {
+	UserFields `json:"-"`
+
+	Status  *Status                `json:"status"`
+	Friends []FetchUserUserFriends `json:"friends"`
+}
+
+func (v *FetchUserUser) UnmarshalJSON(b []byte) error {
+	var fields struct {
+		Status  **Status                `json:"status"`
+		Friends *[]FetchUserUserFriends `json:"friends"`
+	}
+	fields.Status = &v.Status
+	fields.Friends = &v.Friends
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &v.UserFields); err != nil {
+		return err
+	}
+	return nil
+}
+
+type FetchUserUserFriends struct {
+	ID string `json:"id"`
+}
+
+type FetchUserType struct {
+	ID string `json:"id"`
+}
+
+func FetchUser(client *gqlclient.Client, ctx context.Context, id string, withFriends bool) (user *FetchUserUser, type_ *FetchUserType, err error) {
+	op := gqlclient.NewOperation("query fetchUser ($id: ID!, $withFriends: Boolean!) {\n\tuser(id: $id) {\n\t\t... UserFields\n\t\tstatus @skip(if: $withFriends)\n\t\tfriends @include(if: $withFriends) {\n\t\t\tid\n\t\t}\n\t}\n\ttype: user(id: $id) {\n\t\tid\n\t}\n}\nfragment UserFields on User {\n\tid\n\tname\n\tapiUrl\n\timageURLs\n\tcreatedAt\n\tuuid\n\tfavoriteColor\n\t_1st\n\ttype\n}\n")
+	op.Var("id", id)
+	op.Var("withFriends", withFriends)
+	var respData struct {
+		User *FetchUserUser `json:"user"`
+		Type *FetchUserType `json:"type"`
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.User, respData.Type, err
+}
+
+type SearchSearch struct {
+	// Underlying value, depending on the concrete type
+	Value SearchSearchValue `json:"-"`
+}
+
+func (v *SearchSearch) UnmarshalJSON(b []byte) error {
+	var data struct {
+		TypeName string `json:"__typename"`
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	switch data.TypeName {
+	case "User":
+		v.Value = new(SearchSearchUser)
+	case "Bot":
+		v.Value = new(SearchSearchBot)
+	default:
+		return fmt.Errorf("gqlclient: SearchSearch: unknown __typename %q", data.TypeName)
+	}
+	return json.Unmarshal(b, v.Value)
+}
+
+// SearchSearchValue is one of: *SearchSearchUser | *SearchSearchBot
+type SearchSearchValue interface {
+	isSearchSearch()
+}
+
+func (*SearchSearchUser) isSearchSearch() {}
+
+func (*SearchSearchBot) isSearchSearch() {}
+
+type SearchSearchUser struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type SearchSearchBot struct {
+	ID    string               `json:"id"`
+	Owner SearchSearchBotOwner `json:"owner"`
+}
+
+type SearchSearchBotOwner struct {
+	ID string `json:"id"`
+}
+
+func Search(client *gqlclient.Client, ctx context.Context, query string, after *Cursor) (search []SearchSearch, err error) {
+	op := gqlclient.NewOperation("query search ($query: String!, $after: Cursor) {\n\tsearch(query: $query, after: $after) {\n\t\t__typename\n\t\t... on User {\n\t\t\tid\n\t\t\tname\n\t\t}\n\t\t... on Bot {\n\t\t\tid\n\t\t\towner {\n\t\t\t\tid\n\t\t\t}\n\t\t}\n\t}\n}\n")
+	op.Var("query", query)
+	op.Var("after", after)
+	var respData struct {
+		Search []SearchSearch `json:"search"`
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.Search, err
+}
+
+type FetchAPIKeysAPIKeys struct {
+	ID  string `json:"id"`
+	Key string `json:"key"`
+}
+
+func FetchAPIKeys(client *gqlclient.Client, ctx context.Context) (apiKeys []FetchAPIKeysAPIKeys, err error) {
+	op := gqlclient.NewOperation("query fetchAPIKeys {\n\tapiKeys {\n\t\tid\n\t\tkey\n\t}\n}\n")
+	var respData struct {
+		APIKeys []FetchAPIKeysAPIKeys `json:"apiKeys"`
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.APIKeys, err
+}
+
+type UpdateUserUpdateUser struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func UpdateUser(client *gqlclient.Client, ctx context.Context, input UserInput) (updateUser UpdateUserUpdateUser, err error) {
+	op := gqlclient.NewOperation("mutation updateUser ($input: UserInput!) {\n\tupdateUser(input: $input) {\n\t\tid\n\t\tname\n\t}\n}\n")
+	op.Var("input", input)
+	var respData struct {
+		UpdateUser UpdateUserUpdateUser `json:"updateUser"`
+	}
+	err = client.Execute(ctx, op, &respData)
+	return respData.UpdateUser, err
+}
@@ -0,0 +1,101 @@
+package optypes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"git.sr.ht/~emersion/gqlclient"
+)
+
+func newTestClient(t *testing.T, body string) *gqlclient.Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return gqlclient.New(srv.URL, nil)
+}
+
+func TestFetchMe(t *testing.T) {
+	c := newTestClient(t, `{"data":{"me":{
+		"id":"1","name":"emersion","imageURLs":["a"],"createdAt":"2006-01-02T15:04:05Z",
+		"account":{"id":"2","sku":"x"},
+		"friends":[{"id":"3","name":"delthas"}]
+	}}}`)
+
+	me, err := FetchMe(c, context.Background(), nil)
+	if err != nil {
+		t.Fatalf("FetchMe() = %v", err)
+	}
+	// Fields of the embedded fragment are promoted
+	if me.ID != "1" || me.Name != "emersion" || len(me.ImageURLs) != 1 || me.CreatedAt.IsZero() {
+		t.Errorf("fragment fields = %+v", me.UserFields)
+	}
+	if me.Account == nil || me.Account.Sku != "x" {
+		t.Errorf("account = %+v", me.Account)
+	}
+	if len(me.Friends) != 1 || me.Friends[0].Name != "delthas" {
+		t.Errorf("friends = %+v", me.Friends)
+	}
+}
+
+func TestFetchNode(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		check func(t *testing.T, node *FetchNodeNode)
+	}{
+		{
+			name: "user",
+			body: `{"data":{"node":{"__typename":"User","id":"1","createdAt":"2006-01-02T15:04:05Z","status":"ACTIVE"}}}`,
+			check: func(t *testing.T, node *FetchNodeNode) {
+				user, ok := node.Value.(*FetchNodeNodeUser)
+				if !ok {
+					t.Fatalf("value = %#v, want a user", node.Value)
+				}
+				if user.ID != "1" || user.CreatedAt == nil || user.Status == nil || *user.Status != StatusActive_ {
+					t.Errorf("user = %+v", user)
+				}
+			},
+		},
+		{
+			name: "user without conditional fields",
+			body: `{"data":{"node":{"__typename":"User","id":"1"}}}`,
+			check: func(t *testing.T, node *FetchNodeNode) {
+				user := node.Value.(*FetchNodeNodeUser)
+				if user.CreatedAt != nil || user.Status != nil {
+					t.Errorf("user = %+v, want missing fields", user)
+				}
+			},
+		},
+		{
+			name: "bot",
+			body: `{"data":{"node":{"__typename":"Bot","id":"2","owner":{"id":"1","displayName":"emersion"}}}}`,
+			check: func(t *testing.T, node *FetchNodeNode) {
+				bot, ok := node.Value.(*FetchNodeNodeBot)
+				if !ok {
+					t.Fatalf("value = %#v, want a bot", node.Value)
+				}
+				if node.ID != "2" || bot.ID != "2" || bot.Owner.DisplayName != "emersion" {
+					t.Errorf("bot = %+v", bot)
+				}
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			node, err := FetchNode(newTestClient(t, tc.body), context.Background(), "1", true)
+			if err != nil {
+				t.Fatalf("FetchNode() = %v", err)
+			}
+			tc.check(t, node)
+		})
+	}
+
+	_, err := FetchNode(newTestClient(t, `{"data":{"node":{"__typename":"Robot","id":"1"}}}`), context.Background(), "1", true)
+	if err == nil {
+		t.Errorf("FetchNode() with an unknown type succeeded")
+	}
+}
@@ -0,0 +1,38 @@
+// Package shared contains existing Go types bound to GraphQL types when
+// generating the bindings golden file.
+package shared
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Bot is bound to the Bot GraphQL type.
+type Bot struct {
+	ID string `json:"id"`
+}
+
+// Color is bound to the Color GraphQL scalar, e.g. "#ff0000".
+type Color struct {
+	R, G, B uint8
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c Color) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *Color) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if len(s) != 7 {
+		return fmt.Errorf("invalid color %q", s)
+	}
+	if _, err := fmt.Sscanf(s, "#%02x%02x%02x", &c.R, &c.G, &c.B); err != nil {
+		return fmt.Errorf("invalid color %q: %v", s, err)
+	}
+	return nil
+}
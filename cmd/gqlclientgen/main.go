@@ -13,19 +13,54 @@ import (
 	"github.com/vektah/gqlparser/v2"
 	"github.com/vektah/gqlparser/v2/ast"
 	"github.com/vektah/gqlparser/v2/formatter"
+
+	"git.sr.ht/~emersion/gqlclient"
 )
 
 const usage = `usage: gqlclientgen -s <schema> -o <output> [options...]
+       gqlclientgen -c <config> [options...]
 
 Generate Go types and helpers for the specified GraphQL schema.
 
+If neither -s nor -c is specified, the configuration file gqlclientgen.json
+is loaded from the current directory. Options override the configuration
+file.
+
 Options:
 
-  -s <schema>   GraphQL schema, can be specified multiple times. Required.
-  -q <query>    GraphQL query document, can be specified multiple times.
-  -o <output>   Output filename for generated Go code. Required.
+  -c <config>   Configuration file.
+  -s <schema>   GraphQL schema, can be specified multiple times. Glob patterns
+                are accepted. Required without a configuration file.
+  -q <query>    GraphQL query document, can be specified multiple times. Glob
+                patterns are accepted.
+  -o <output>   Output filename for generated Go code. Required without a
+                configuration file.
   -n <package>  Go package name, defaults to the dirname of the output file.
   -d            Omit deprecated fields and enum values
+  -O            Use gqlclient.Omittable for nullable input object fields, to
+                distinguish omitted fields from explicit nulls
+  -t            Generate a dedicated type per operation and nested selection,
+                containing exactly the selected fields, and a type per named
+                fragment. Required for aliases in nested fields.
+  -i <name>     Generate an interface with the given name listing the
+                operations, an implementation created with New<name> and a
+                mock implementation named Mock<name>
+  -S <name>=<type>
+                Map the GraphQL scalar to a fully qualified Go type which
+                implements json.Marshaler and json.Unmarshaler (e.g.
+                github.com/google/uuid.UUID). Can be specified multiple times.
+  -C <name>=<type>
+                Map the GraphQL scalar to a fully qualified Go type, encoded
+                and decoded with the codec registered at runtime via
+                gqlclient.RegisterScalar. Can be specified multiple times.
+  -B <name>=<type>
+                Bind the GraphQL type to a fully qualified existing Go type
+                (e.g. example.org/shared.User) instead of generating it. Can be
+                specified multiple times.
+  -I <initialism>
+                Write the initialism in upper case in generated identifiers
+                (e.g. SKU), in addition to the common ones such as ID and URL.
+                Can be specified multiple times.
 `
 
 type stringSliceFlag []string
@@ -39,7 +74,94 @@ func (v *stringSliceFlag) Set(s string) error {
 	return nil
 }
 
-const gqlclient = "git.sr.ht/~emersion/gqlclient"
+const gqlclientPath = "git.sr.ht/~emersion/gqlclient"
+
+var (
+	// typeBindings maps GraphQL type names to existing Go types, which
+	// implement json.Marshaler and json.Unmarshaler for scalars
+	typeBindings map[string]string
+	// scalarCodecs maps GraphQL scalar names to Go types, for scalars encoded
+	// with a codec registered at runtime
+	scalarCodecs map[string]string
+	// typeNames maps GraphQL type names to generated Go type names, when
+	// they differ
+	typeNames map[string]string
+	// conditionalFields lists by GraphQL type name the fields which are
+	// selected with @skip or @include in nested selection sets, when schema
+	// types are used for operations
+	conditionalFields map[string]map[string]bool
+)
+
+// goTypeName returns the name of the Go type generated for a GraphQL type.
+func goTypeName(name string) string {
+	if goName, ok := typeNames[name]; ok {
+		return goName
+	}
+	return goName(name)
+}
+
+// isBound checks whether a GraphQL type is bound to an existing Go type.
+func isBound(name string) bool {
+	_, ok := typeBindings[name]
+	return ok
+}
+
+// reserveTypeName adds the Go names of the types generated for a GraphQL type
+// to declared, renaming them on conflict.
+func reserveTypeName(def *ast.Definition) {
+	var suffixes []string
+	switch def.Kind {
+	case ast.Interface, ast.Union:
+		suffixes = []string{"Value"}
+	}
+
+	name := goTypeName(def.Name)
+	for {
+		taken := declared[name]
+		for _, suffix := range suffixes {
+			taken = taken || declared[name+suffix]
+		}
+		if !taken {
+			break
+		}
+		name += "_"
+	}
+	if name != goTypeName(def.Name) {
+		typeNames[def.Name] = name
+	}
+
+	declared[name] = true
+	for _, suffix := range suffixes {
+		declared[name+suffix] = true
+	}
+}
+
+// genNamedType generates a reference to the Go type of a GraphQL type
+// defined in the schema.
+func genNamedType(name string) jen.Code {
+	if goType, ok := typeBindings[name]; ok {
+		return genGoType(goType)
+	}
+	return jen.Id(goTypeName(name))
+}
+
+func parseBinding(kv string) (name, goType string) {
+	parts := strings.SplitN(kv, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		log.Fatalf("in binding %q: expected <name>=<type>", kv)
+	}
+	return parts[0], parts[1]
+}
+
+// genGoType generates a reference to a Go type, either predeclared (e.g.
+// "string") or fully qualified (e.g. "github.com/google/uuid.UUID").
+func genGoType(goType string) jen.Code {
+	i := strings.LastIndex(goType, ".")
+	if i < 0 || i < strings.LastIndex(goType, "/") || strings.ContainsAny(goType, "[]{}*") {
+		return jen.Id(goType)
+	}
+	return jen.Qual(goType[:i], goType[i+1:])
+}
 
 func genDescription(s string) jen.Code {
 	if s == "" {
@@ -77,27 +199,28 @@ func genType(schema *ast.Schema, t *ast.Type) jen.Code {
 		gen = jen.Bool()
 	case "ID":
 		gen = jen.String()
-	// Convenience types
-	case "Time":
-		gen = jen.Qual(gqlclient, "Time")
-	case "Map":
-		gen = jen.Map(jen.String()).Interface()
-	case "Upload":
-		gen = jen.Qual(gqlclient, "Upload")
-	case "Any":
-		gen = jen.Interface()
 	default:
+		if isBound(def.Name) {
+			gen = genNamedType(def.Name)
+			break
+		}
+		if _, ok := scalarCodecs[def.Name]; ok {
+			gen = jen.Id(goTypeName(def.Name))
+			break
+		}
+		if s := gqlclient.LookupScalar(def.Name); s != nil && def.Kind == ast.Scalar {
+			gen = genGoType(s.GoType)
+			break
+		}
 		if def.BuiltIn {
 			panic(fmt.Sprintf("unsupported built-in type: %s", def.Name))
 		}
-		gen = jen.Id(def.Name)
+		gen = genNamedType(def.Name)
 	}
 
 	if !t.NonNull {
-		switch def.Name {
-		case "ID", "Time", "Map", "Any":
-			// These don't need a pointer, they have a recognizable zero value
-		default:
+		// Types with a recognizable zero value don't need a pointer
+		if !hasRecognizableZero(def.Name) {
 			prefix = append(prefix, jen.Op("*"))
 		}
 	} else if toplevel {
@@ -111,43 +234,83 @@ func genType(schema *ast.Schema, t *ast.Type) jen.Code {
 	return jen.Add(prefix...).Add(gen)
 }
 
+// genOptionalType returns the Go type of a field which may be missing from the
+// response. Unlike nullable fields, types with a recognizable zero value use a
+// pointer as well, so that a missing field can be told apart from a zero
+// value. Lists are left as-is, a missing list is nil.
+func genOptionalType(schema *ast.Schema, t *ast.Type) jen.Code {
+	typ := genType(schema, optionalType(t))
+	if t.Elem == nil && hasRecognizableZero(t.NamedType) {
+		return jen.Op("*").Add(typ)
+	}
+	return typ
+}
+
+// hasRecognizableZero checks whether the Go type generated for a GraphQL type
+// has a zero value which can stand for null.
+func hasRecognizableZero(name string) bool {
+	if name == "ID" {
+		return true
+	}
+	if isBound(name) {
+		return false
+	}
+	if _, ok := scalarCodecs[name]; ok {
+		return false
+	}
+	s := gqlclient.LookupScalar(name)
+	return s != nil && s.RecognizableZero
+}
+
+// genFieldDefType returns the Go type of a field of an object or interface
+// type.
+func genFieldDefType(schema *ast.Schema, def *ast.Definition, field *ast.FieldDefinition) jen.Code {
+	if conditionalFields[def.Name][field.Name] {
+		return genOptionalType(schema, field.Type)
+	}
+	return genType(schema, field.Type)
+}
+
 func hasDeprecated(list ast.DirectiveList) bool {
 	return list.ForName("deprecated") != nil
 }
 
-func genDef(schema *ast.Schema, def *ast.Definition, omitDeprecated bool) *jen.Statement {
+func genDef(schema *ast.Schema, def *ast.Definition, omitDeprecated, omittable bool) *jen.Statement {
+	if isBound(def.Name) {
+		return nil
+	}
+
 	switch def.Kind {
 	case ast.Scalar:
-		switch def.Name {
-		case "Time", "Map", "Upload", "Any":
-			// Convenience types
+		if goType, ok := scalarCodecs[def.Name]; ok {
+			return genScalarCodec(def.Name, goType)
+		}
+		if gqlclient.LookupScalar(def.Name) != nil {
+			// Bound to an existing Go type
 			return nil
-		default:
-			return jen.Type().Id(def.Name).String()
 		}
+		return jen.Type().Id(goTypeName(def.Name)).String()
 	case ast.Enum:
 		var defs []jen.Code
 		for _, val := range def.EnumValues {
 			if omitDeprecated && hasDeprecated(val.Directives) {
 				continue
 			}
-			nameWords := strings.Split(strings.ToLower(val.Name), "_")
-			for i := range nameWords {
-				nameWords[i] = strings.Title(nameWords[i])
-			}
-			name := strings.Join(nameWords, "")
+			name := declared.add(goTypeName(def.Name) + goName(val.Name))
 			desc := genDescription(val.Description)
 			defs = append(defs,
-				jen.Add(desc).Id(def.Name+name).Id(def.Name).Op("=").Lit(val.Name),
+				jen.Add(desc).Id(name).Id(goTypeName(def.Name)).Op("=").Lit(val.Name),
 			)
 		}
 		return jen.Add(
-			jen.Type().Id(def.Name).String(),
+			jen.Type().Id(goTypeName(def.Name)).String(),
 			jen.Line(),
 			jen.Const().Defs(defs...),
 		)
 	case ast.Object, ast.InputObject:
-		var fields []jen.Code
+		var fields, marshalStmts []jen.Code
+		hasOmittable := false
+		fieldNames := make(identSet)
 		for _, field := range def.Fields {
 			if omitDeprecated && hasDeprecated(field.Directives) {
 				continue
@@ -155,27 +318,61 @@ func genDef(schema *ast.Schema, def *ast.Definition, omitDeprecated bool) *jen.S
 			if field.Name == "__schema" || field.Name == "__type" {
 				continue // TODO
 			}
-			name := strings.Title(field.Name)
+			name := fieldNames.add(goName(field.Name))
 			jsonTag := field.Name
-			if !field.Type.NonNull {
-				jsonTag += ",omitempty"
+			typ := genFieldDefType(schema, def, field)
+			if def.Kind == ast.InputObject && omittable && !field.Type.NonNull {
+				nonNull := *field.Type
+				nonNull.NonNull = true
+				typ = jen.Qual(gqlclientPath, "Omittable").Types(genType(schema, &nonNull))
+				hasOmittable = true
+				marshalStmts = append(marshalStmts, jen.If(jen.Op("!").Id("v").Dot(name).Dot("IsOmitted").Call()).Block(
+					jen.Id("m").Index(jen.Lit(field.Name)).Op("=").Id("v").Dot(name),
+				))
+			} else {
+				if !field.Type.NonNull {
+					jsonTag += ",omitempty"
+				}
+				marshalStmts = append(marshalStmts, jen.Id("m").Index(jen.Lit(field.Name)).Op("=").Id("v").Dot(name))
 			}
 			tag := jen.Tag(map[string]string{"json": jsonTag})
 			desc := genDescription(field.Description)
 			fields = append(fields,
-				jen.Add(desc).Id(name).Add(genType(schema, field.Type)).Add(tag),
+				jen.Add(desc).Id(name).Add(typ).Add(tag),
 			)
 		}
-		return jen.Type().Id(def.Name).Struct(fields...)
+		stmt := jen.Type().Id(goTypeName(def.Name)).Struct(fields...)
+		if !hasOmittable {
+			return stmt
+		}
+
+		// Omitted fields need to be skipped when marshaling
+		var stmts []jen.Code
+		stmts = append(stmts, jen.Id("m").Op(":=").Make(jen.Map(jen.String()).Interface()))
+		stmts = append(stmts, marshalStmts...)
+		stmts = append(stmts, jen.Return(jen.Qual("encoding/json", "Marshal").Call(jen.Id("m"))))
+		return jen.Add(
+			stmt,
+			jen.Line(),
+			jen.Line(),
+			jen.Func().Params(jen.Id("v").Id(goTypeName(def.Name))).Id("MarshalJSON").Params().Params(jen.Index().Byte(), jen.Error()).Block(stmts...),
+		)
 	case ast.Interface, ast.Union:
 		possibleTypes := schema.GetPossibleTypes(def)
 
-		var typeNames []string
+		var valueTypeNames []string
+		hasBound := false
 		for _, typ := range possibleTypes {
-			typeNames = append(typeNames, typ.Name)
+			if goType, ok := typeBindings[typ.Name]; ok {
+				valueTypeNames = append(valueTypeNames, goType[strings.LastIndex(goType, "/")+1:])
+				hasBound = true
+			} else {
+				valueTypeNames = append(valueTypeNames, goTypeName(typ.Name))
+			}
 		}
 
 		var fields []jen.Code
+		fieldNames := identSet{"Value": true}
 		for _, field := range def.Fields {
 			if omitDeprecated && hasDeprecated(field.Directives) {
 				continue
@@ -183,7 +380,7 @@ func genDef(schema *ast.Schema, def *ast.Definition, omitDeprecated bool) *jen.S
 			if field.Name == "__schema" || field.Name == "__type" {
 				continue // TODO
 			}
-			name := strings.Title(field.Name)
+			name := fieldNames.add(goName(field.Name))
 			jsonTag := field.Name
 			if !field.Type.NonNull {
 				jsonTag += ",omitempty"
@@ -191,7 +388,7 @@ func genDef(schema *ast.Schema, def *ast.Definition, omitDeprecated bool) *jen.S
 			tag := jen.Tag(map[string]string{"json": jsonTag})
 			desc := genDescription(field.Description)
 			fields = append(fields,
-				jen.Add(desc).Id(name).Add(genType(schema, field.Type)).Add(tag),
+				jen.Add(desc).Id(name).Add(genFieldDefType(schema, def, field)).Add(tag),
 			)
 		}
 		if len(fields) > 0 {
@@ -199,13 +396,13 @@ func genDef(schema *ast.Schema, def *ast.Definition, omitDeprecated bool) *jen.S
 		}
 		fields = append(fields,
 			jen.Comment("Underlying value of the GraphQL "+strings.ToLower(string(def.Kind))),
-			jen.Id("Value").Id(def.Name+"Value").Tag(map[string]string{"json": "-"}),
+			jen.Id("Value").Id(goTypeName(def.Name)+"Value").Tag(map[string]string{"json": "-"}),
 		)
 
 		var cases []jen.Code
 		for _, typ := range possibleTypes {
 			cases = append(cases, jen.Case(jen.Lit(typ.Name)).Block(
-				jen.Id("base").Dot("Value").Op("=").New(jen.Id(typ.Name)),
+				jen.Id("base").Dot("Value").Op("=").New(genNamedType(typ.Name)),
 			))
 		}
 
@@ -227,16 +424,16 @@ func genDef(schema *ast.Schema, def *ast.Definition, omitDeprecated bool) *jen.S
 		)
 
 		var stmts []jen.Code
-		stmts = append(stmts, jen.Type().Id(def.Name).Struct(fields...))
+		stmts = append(stmts, jen.Type().Id(goTypeName(def.Name)).Struct(fields...))
 		stmts = append(stmts, jen.Line())
 		stmts = append(stmts, jen.Func().Params(
-			jen.Id("base").Op("*").Id(def.Name),
+			jen.Id("base").Op("*").Id(goTypeName(def.Name)),
 		).Id("UnmarshalJSON").Params(
 			jen.Id("b").Index().Byte(),
 		).Params(
 			jen.Id("error"),
 		).Block(
-			jen.Type().Id("Raw").Id(def.Name),
+			jen.Type().Id("Raw").Id(goTypeName(def.Name)),
 			jen.Var().Id("data").Struct(
 				jen.Op("*").Id("Raw"),
 				jen.Id("TypeName").String().Tag(map[string]string{"json": "__typename"}),
@@ -254,23 +451,67 @@ func genDef(schema *ast.Schema, def *ast.Definition, omitDeprecated bool) *jen.S
 			)),
 		))
 		stmts = append(stmts, jen.Line())
-		stmts = append(stmts, jen.Comment(def.Name+"Value is one of: "+strings.Join(typeNames, " | ")).Line())
-		stmts = append(stmts, jen.Type().Id(def.Name+"Value").Interface(
-			jen.Id("is"+def.Name).Params(),
-		))
+		stmts = append(stmts, jen.Comment(goTypeName(def.Name)+"Value is one of: "+strings.Join(valueTypeNames, " | ")).Line())
+		if hasBound {
+			// Marker methods can't be defined on bound types
+			stmts = append(stmts, jen.Type().Id(goTypeName(def.Name)+"Value").Interface())
+		} else {
+			stmts = append(stmts, jen.Type().Id(goTypeName(def.Name)+"Value").Interface(
+				jen.Id("is"+def.Name).Params(),
+			))
+		}
 		return jen.Add(stmts...)
 	default:
 		panic(fmt.Sprintf("unsupported definition kind: %s", def.Kind))
 	}
 }
 
+// genScalarCodec generates a wrapper type for a scalar encoded with a codec
+// registered at runtime.
+func genScalarCodec(name, goType string) *jen.Statement {
+	goName := goTypeName(name)
+	return jen.Add(
+		jen.Type().Id(goName).Struct(jen.Id("Value").Add(genGoType(goType))),
+		jen.Line(),
+		jen.Func().Params(jen.Id("v").Id(goName)).Id("MarshalJSON").Params().Params(jen.Index().Byte(), jen.Error()).Block(
+			jen.Return(jen.Qual(gqlclientPath, "MarshalScalar").Call(jen.Lit(name), jen.Op("&").Id("v").Dot("Value"))),
+		),
+		jen.Line(),
+		jen.Line(),
+		jen.Func().Params(jen.Id("v").Op("*").Id(goName)).Id("UnmarshalJSON").Params(jen.Id("b").Index().Byte()).Error().Block(
+			jen.Return(jen.Qual(gqlclientPath, "UnmarshalScalar").Call(jen.Lit(name), jen.Id("b"), jen.Op("&").Id("v").Dot("Value"))),
+		),
+	)
+}
+
+// copySelectionSet returns a deep copy of a selection set. Fragment spreads
+// are shared.
+func copySelectionSet(selSet ast.SelectionSet) ast.SelectionSet {
+	if selSet == nil {
+		return nil
+	}
+	l := make(ast.SelectionSet, len(selSet))
+	for i, sel := range selSet {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			field := *sel
+			field.SelectionSet = copySelectionSet(sel.SelectionSet)
+			l[i] = &field
+		case *ast.InlineFragment:
+			frag := *sel
+			frag.SelectionSet = copySelectionSet(sel.SelectionSet)
+			l[i] = &frag
+		default:
+			l[i] = sel
+		}
+	}
+	return l
+}
+
 func collectFragments(frags map[*ast.FragmentDefinition]struct{}, selSet ast.SelectionSet) {
 	for _, sel := range selSet {
 		switch sel := sel.(type) {
 		case *ast.Field:
-			if sel.Name != sel.Alias {
-				panic(fmt.Sprintf("field aliases aren't supported"))
-			}
 			collectFragments(frags, sel.SelectionSet)
 		case *ast.FragmentSpread:
 			frags[sel.Definition] = struct{}{}
@@ -283,7 +524,67 @@ func collectFragments(frags map[*ast.FragmentDefinition]struct{}, selSet ast.Sel
 	}
 }
 
-func genOp(schema *ast.Schema, op *ast.OperationDefinition) *jen.Statement {
+// findNestedAlias returns the first alias in nested fields. Nested fields are
+// decoded into schema types named after the schema fields, so aliases are only
+// supported there with per-operation types.
+func findNestedAlias(selSet ast.SelectionSet, nested bool) (string, bool) {
+	for _, sel := range selSet {
+		var alias string
+		var ok bool
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if nested && sel.Name != sel.Alias {
+				return sel.Alias, true
+			}
+			alias, ok = findNestedAlias(sel.SelectionSet, true)
+		case *ast.FragmentSpread:
+			alias, ok = findNestedAlias(sel.Definition.SelectionSet, nested)
+		case *ast.InlineFragment:
+			alias, ok = findNestedAlias(sel.SelectionSet, nested)
+		}
+		if ok {
+			return alias, true
+		}
+	}
+	return "", false
+}
+
+// collectConditionalFields adds the fields selected with @skip or @include in a
+// selection set to conditionalFields.
+func collectConditionalFields(schema *ast.Schema, selSet ast.SelectionSet, conditional bool) {
+	for _, sel := range selSet {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if conditional || isConditional(sel.Directives) {
+				addConditionalField(schema, sel.ObjectDefinition, sel.Name)
+			}
+			collectConditionalFields(schema, sel.SelectionSet, false)
+		case *ast.FragmentSpread:
+			collectConditionalFields(schema, sel.Definition.SelectionSet, conditional || isConditional(sel.Directives))
+		case *ast.InlineFragment:
+			collectConditionalFields(schema, sel.SelectionSet, conditional || isConditional(sel.Directives))
+		}
+	}
+}
+
+// addConditionalField marks a field as conditional on a type. The response
+// for an abstract type is decoded both into the abstract type and into the
+// concrete type, so related types are marked as well.
+func addConditionalField(schema *ast.Schema, def *ast.Definition, name string) {
+	defs := []*ast.Definition{def}
+	defs = append(defs, schema.GetPossibleTypes(def)...)
+	defs = append(defs, schema.GetImplements(def)...)
+	for _, def := range defs {
+		if conditionalFields[def.Name] == nil {
+			conditionalFields[def.Name] = make(map[string]bool)
+		}
+		conditionalFields[def.Name][name] = true
+	}
+}
+
+// genOp generates a function for an operation. If g is non-nil, per-operation
+// types are generated, otherwise schema types are used.
+func genOp(schema *ast.Schema, op *ast.OperationDefinition, g *selectionGen) (*jen.Statement, *opFunc) {
 	frags := make(map[*ast.FragmentDefinition]struct{})
 	collectFragments(frags, op.SelectionSet)
 
@@ -291,39 +592,105 @@ func genOp(schema *ast.Schema, op *ast.OperationDefinition) *jen.Statement {
 	for frag := range frags {
 		fragList = append(fragList, frag)
 	}
+	sort.Slice(fragList, func(i, j int) bool {
+		return fragList[i].Name < fragList[j].Name
+	})
 
+	// Generated union and interface types require __typename. Inject it
+	// into a copy, the original document is shared by all operations and
+	// used to generate types.
 	var query ast.QueryDocument
-	query.Operations = ast.OperationList{op}
-	query.Fragments = fragList
+	opCopy := *op
+	opCopy.SelectionSet = copySelectionSet(op.SelectionSet)
+	query.Operations = ast.OperationList{&opCopy}
+	for _, frag := range fragList {
+		fragCopy := *frag
+		fragCopy.SelectionSet = copySelectionSet(frag.SelectionSet)
+		query.Fragments = append(query.Fragments, &fragCopy)
+	}
+	gqlclient.InjectTypename(&query, schema)
 
 	var sb strings.Builder
 	formatter.NewFormatter(&sb).FormatQueryDocument(&query)
 	queryStr := sb.String()
 
-	var stmts, in, out, ret, dataFields []jen.Code
+	var defs, stmts, in, out, ret, dataFields []jen.Code
+	fn := &opFunc{name: declared.add(goName(op.Name))}
+	// Local identifiers used by the generated function
+	locals := identSet{"client": true, "ctx": true, "op": true, "respData": true, "err": true}
+	dataFieldNames := make(identSet)
 
-	in = append(in, jen.Id("client").Op("*").Qual(gqlclient, "Client"))
+	in = append(in, jen.Id("client").Op("*").Qual(gqlclientPath, "Client"))
 	in = append(in, jen.Id("ctx").Qual("context", "Context"))
 
-	stmts = append(stmts, jen.Id("op").Op(":=").Qual(gqlclient, "NewOperation").Call(jen.Lit(queryStr)))
+	stmts = append(stmts, jen.Id("op").Op(":=").Qual(gqlclientPath, "NewOperation").Call(jen.Lit(queryStr)))
 
 	for _, v := range op.VariableDefinitions {
-		in = append(in, jen.Id(v.Variable).Add(genType(schema, v.Type)))
+		typ := genType(schema, v.Type)
+		name := locals.add(goVarName(v.Variable))
+		in = append(in, jen.Id(name).Add(typ))
+		fn.params = append(fn.params, opParam{name, typ})
 		stmts = append(stmts, jen.Id("op").Dot("Var").Call(
 			jen.Lit(v.Variable),
-			jen.Id(v.Variable),
+			jen.Id(name),
 		))
 	}
 
-	for _, sel := range op.SelectionSet {
-		field, ok := sel.(*ast.Field)
-		if !ok {
-			panic(fmt.Sprintf("unsupported selection %T", sel))
+	if g != nil {
+		var root *ast.Definition
+		switch op.Operation {
+		case ast.Query:
+			root = schema.Query
+		case ast.Mutation:
+			root = schema.Mutation
+		case ast.Subscription:
+			root = schema.Subscription
+		}
+
+		for _, sf := range collectSelection(schema, root, op.SelectionSet, false).fields {
+			typ := g.genFieldType(fn.name, sf)
+			tag := jen.Tag(map[string]string{"json": sf.key})
+			name := locals.add(goVarName(sf.key))
+			fieldName := dataFieldNames.add(goName(sf.key))
+			out = append(out, jen.Id(name).Add(typ))
+			fn.results = append(fn.results, opParam{name, typ})
+			ret = append(ret, jen.Id("respData").Dot(fieldName))
+			dataFields = append(dataFields, jen.Id(fieldName).Add(typ).Add(tag))
+		}
+		for _, def := range g.defs {
+			defs = append(defs, def, jen.Line(), jen.Line())
+		}
+		g.defs = nil
+	} else {
+		seen := make(map[string]bool)
+		for _, sel := range op.SelectionSet {
+			field, ok := sel.(*ast.Field)
+			if !ok {
+				panic(fmt.Sprintf("unsupported selection %T", sel))
+			}
+			// The same response key may be selected multiple times, the
+			// selections are merged by the server
+			key := field.Alias
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			var typ jen.Code
+			if isConditional(field.Directives) {
+				// The field may be missing from the response
+				typ = genOptionalType(schema, field.Definition.Type)
+			} else {
+				typ = genType(schema, field.Definition.Type)
+			}
+			tag := jen.Tag(map[string]string{"json": key})
+			name := locals.add(goVarName(key))
+			fieldName := dataFieldNames.add(goName(key))
+			out = append(out, jen.Id(name).Add(typ))
+			fn.results = append(fn.results, opParam{name, typ})
+			ret = append(ret, jen.Id("respData").Dot(fieldName))
+			dataFields = append(dataFields, jen.Id(fieldName).Add(typ).Add(tag))
 		}
-		typ := genType(schema, field.Definition.Type)
-		out = append(out, jen.Id(field.Name).Add(typ))
-		ret = append(ret, jen.Id("respData").Dot(strings.Title(field.Name)))
-		dataFields = append(dataFields, jen.Id(strings.Title(field.Name)).Add(typ))
 	}
 
 	out = append(out, jen.Id("err").Id("error"))
@@ -341,37 +708,139 @@ func genOp(schema *ast.Schema, op *ast.OperationDefinition) *jen.Statement {
 
 	stmts = append(stmts, jen.Return(ret...))
 
-	name := strings.Title(op.Name)
-	return jen.Func().Id(name).Params(in...).Params(out...).Block(stmts...)
+	return jen.Add(defs...).Func().Id(fn.name).Params(in...).Params(out...).Block(stmts...), fn
 }
 
 func main() {
-	var schemaFilenames, queryFilenames []string
-	var pkgName, outputFilename string
-	var omitDeprecated bool
+	var configFilename string
+	var schemaFilenames, queryFilenames, scalarBindings, scalarCodecBindings, typeBindingFlags, initialismFlags []string
+	var flags target
+	flag.StringVar(&configFilename, "c", "", "configuration file")
 	flag.Var((*stringSliceFlag)(&schemaFilenames), "s", "schema filename")
 	flag.Var((*stringSliceFlag)(&queryFilenames), "q", "query filename")
-	flag.StringVar(&pkgName, "n", "", "package name")
-	flag.StringVar(&outputFilename, "o", "", "output filename")
-	flag.BoolVar(&omitDeprecated, "d", false, "omit deprecated fields")
+	flag.StringVar(&flags.Package, "n", "", "package name")
+	flag.StringVar(&flags.Output, "o", "", "output filename")
+	flag.BoolVar(&flags.OmitDeprecated, "d", false, "omit deprecated fields")
+	flag.BoolVar(&flags.Omittable, "O", false, "use gqlclient.Omittable for nullable input fields")
+	flag.BoolVar(&flags.OperationTypes, "t", false, "generate per-operation types")
+	flag.StringVar(&flags.Interface, "i", "", "client interface name")
+	flag.Var((*stringSliceFlag)(&scalarBindings), "S", "scalar binding")
+	flag.Var((*stringSliceFlag)(&scalarCodecBindings), "C", "scalar codec binding")
+	flag.Var((*stringSliceFlag)(&typeBindingFlags), "B", "type binding")
+	flag.Var((*stringSliceFlag)(&initialismFlags), "I", "initialism")
 	flag.Usage = func() {
 		fmt.Fprint(os.Stderr, usage)
 	}
 	flag.Parse()
 
-	if len(schemaFilenames) == 0 || outputFilename == "" || len(flag.Args()) > 0 {
+	if len(flag.Args()) > 0 {
 		flag.Usage()
 		os.Exit(1)
 	}
 
+	if configFilename == "" && len(schemaFilenames) == 0 {
+		if _, err := os.Stat(defaultConfigFilename); err == nil {
+			configFilename = defaultConfigFilename
+		}
+	}
+
+	targets := []*target{{}}
+	if configFilename != "" {
+		cfg, err := loadConfig(configFilename)
+		if err != nil {
+			log.Fatalf("failed to load config file %q: %v", configFilename, err)
+		}
+		targets = cfg.Targets
+	}
+
+	// Flags override the configuration file
+	isSet := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		isSet[f.Name] = true
+	})
+	if len(targets) > 1 && (isSet["o"] || isSet["n"]) {
+		log.Fatalf("-o and -n cannot be used with multiple targets")
+	}
+	for _, t := range targets {
+		if isSet["s"] {
+			t.Schema = schemaFilenames
+		}
+		if isSet["q"] {
+			t.Queries = queryFilenames
+		}
+		if isSet["o"] {
+			t.Output = flags.Output
+		}
+		if isSet["n"] {
+			t.Package = flags.Package
+		}
+		if isSet["d"] {
+			t.OmitDeprecated = flags.OmitDeprecated
+		}
+		if isSet["O"] {
+			t.Omittable = flags.Omittable
+		}
+		if isSet["t"] {
+			t.OperationTypes = flags.OperationTypes
+		}
+		if isSet["i"] {
+			t.Interface = flags.Interface
+		}
+		t.Scalars = mergeMaps(t.Scalars, nil)
+		for _, kv := range scalarBindings {
+			name, goType := parseBinding(kv)
+			t.Scalars[name] = goType
+		}
+		t.Bindings = mergeMaps(t.Bindings, nil)
+		for _, kv := range typeBindingFlags {
+			name, goType := parseBinding(kv)
+			t.Bindings[name] = goType
+		}
+		t.ScalarCodecs = mergeMaps(t.ScalarCodecs, nil)
+		for _, kv := range scalarCodecBindings {
+			name, goType := parseBinding(kv)
+			t.ScalarCodecs[name] = goType
+		}
+		t.Initialisms = append(t.Initialisms, initialismFlags...)
+
+		if len(t.Schema) == 0 || t.Output == "" {
+			flag.Usage()
+			os.Exit(1)
+		}
+	}
+
+	for _, t := range targets {
+		generate(t)
+	}
+}
+
+// generate generates the Go file for a target.
+func generate(t *target) {
+	typeBindings = mergeMaps(t.Scalars, t.Bindings)
+	scalarCodecs = t.ScalarCodecs
+	typeNames = mergeMaps(t.TypeNames, nil)
+	setInitialisms(t.Initialisms)
+	declared = make(identSet)
+	conditionalFields = make(map[string]map[string]bool)
+
+	pkgName := t.Package
 	if pkgName == "" {
-		abs, err := filepath.Abs(outputFilename)
+		abs, err := filepath.Abs(t.Output)
 		if err != nil {
 			log.Fatalf("failed to get absolute output filename: %v", err)
 		}
 		pkgName = filepath.Base(filepath.Dir(abs))
 	}
 
+	schemaFilenames, err := expandGlobs(t.Schema)
+	if err != nil {
+		log.Fatalf("failed to find schema files: %v", err)
+	}
+	queryFilenames, err := expandGlobs(t.Queries)
+	if err != nil {
+		log.Fatalf("failed to find query files: %v", err)
+	}
+
 	var sources []*ast.Source
 	for _, filename := range schemaFilenames {
 		b, err := os.ReadFile(filename)
@@ -398,40 +867,96 @@ func main() {
 			log.Fatalf("failed to parse query %q: %v", filename, gqlErr)
 		}
 
+		// TODO: generate subscription functions once the client supports a
+		// subscription transport
+		for _, op := range q.Operations {
+			if op.Operation == ast.Subscription {
+				log.Fatalf("in query %q: subscription %q: subscriptions are not supported yet", filename, op.Name)
+			}
+			if t.OperationTypes {
+				continue
+			}
+			for _, sel := range op.SelectionSet {
+				if field, ok := sel.(*ast.Field); ok {
+					collectConditionalFields(schema, field.SelectionSet, false)
+				}
+			}
+			if alias, ok := findNestedAlias(op.SelectionSet, false); ok {
+				log.Fatalf("in query %q: operation %q: nested field alias %q requires per-operation types, pass -t", filename, op.Name, alias)
+			}
+		}
+
 		queries = append(queries, q)
 	}
 
 	f := jen.NewFile(pkgName)
 	f.HeaderComment("Code generated by gqlclientgen - DO NOT EDIT.")
 
-	var typeNames []string
+	var defNames []string
 	for _, def := range schema.Types {
 		if def.BuiltIn || def == schema.Query || def == schema.Mutation || def == schema.Subscription {
 			continue
 		}
-		typeNames = append(typeNames, def.Name)
+		defNames = append(defNames, def.Name)
 	}
 
-	sort.Strings(typeNames)
+	sort.Strings(defNames)
+
+	// Reserve type names first, so that other identifiers are renamed on
+	// conflict. Names set in the configuration are reserved before the
+	// others, which are renamed if several GraphQL names map to the same Go
+	// name.
+	for _, explicit := range []bool{true, false} {
+		for _, name := range defNames {
+			if _, ok := t.TypeNames[name]; ok != explicit || isBound(name) {
+				continue
+			}
+			reserveTypeName(schema.Types[name])
+		}
+	}
+	if t.Interface != "" {
+		// The interface names are chosen by the user, so they can't be
+		// renamed on conflict
+		for _, name := range []string{t.Interface, "New" + t.Interface, "default" + t.Interface, "Mock" + t.Interface} {
+			if declared[name] {
+				log.Fatalf("client interface %q: identifier %q conflicts with a generated type", t.Interface, name)
+			}
+			declared[name] = true
+		}
+	}
 
-	for _, name := range typeNames {
+	for _, name := range defNames {
 		def := schema.Types[name]
-		stmt := genDef(schema, def, omitDeprecated)
+		stmt := genDef(schema, def, t.OmitDeprecated, t.Omittable)
 		if stmt != nil {
 			f.Add(genDescription(def.Description), stmt).Line()
 		}
+		if isBound(def.Name) {
+			continue
+		}
 		for _, typ := range schema.GetImplements(def) {
 			f.Func().Params(genType(schema, ast.NamedType(def.Name, nil))).Id("is" + typ.Name).Params().Block().Line()
 		}
 	}
 
+	var funcs []*opFunc
+	var selGen *selectionGen
+	if t.OperationTypes {
+		selGen = newSelectionGen(schema)
+	}
 	for _, q := range queries {
 		for _, op := range q.Operations {
-			f.Add(genOp(schema, op)).Line()
+			stmt, fn := genOp(schema, op, selGen)
+			f.Add(stmt).Line()
+			funcs = append(funcs, fn)
 		}
 	}
 
-	if err := f.Save(outputFilename); err != nil {
+	if t.Interface != "" {
+		f.Add(genClient(t.Interface, funcs))
+	}
+
+	if err := f.Save(t.Output); err != nil {
 		log.Fatalf("failed to save output file: %v", err)
 	}
 }
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// mainEnv is set when the test binary is re-executed to run gqlclientgen.
+const mainEnv = "GQLCLIENTGEN_TEST_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(mainEnv) == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runMain runs gqlclientgen with the specified arguments. It's run in a
+// separate process, because it exits on error.
+func runMain(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), mainEnv+"=1")
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+// The golden files are regular packages of this module, so they are built,
+// vetted and tested along with it. Run "go test -update" to regenerate them.
+var goldenTests = []struct {
+	name string
+	args []string
+}{
+	{
+		name: "basic",
+		args: []string{
+			"-s", "testdata/schema.graphqls",
+			"-q", "testdata/queries/users.graphql",
+			"-n", "basic",
+			"-d", "-O", "-i", "Client",
+		},
+	},
+	{
+		name: "optypes",
+		args: []string{
+			"-s", "testdata/schema.graphqls",
+			"-q", "testdata/queries/*.graphql",
+			"-n", "optypes",
+			"-t",
+		},
+	},
+	{
+		// Flags override and complete the configuration file
+		name: "bindings",
+		args: []string{
+			"-c", "testdata/gqlclientgen.json",
+			"-O=false",
+			"-S", "Color=git.sr.ht/~emersion/gqlclient/cmd/gqlclientgen/internal/golden/shared.Color",
+		},
+	},
+}
+
+func TestGolden(t *testing.T) {
+	for _, tc := range goldenTests {
+		t.Run(tc.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "gql.go")
+			if out, err := runMain(t, append(tc.args, "-o", output)...); err != nil {
+				t.Fatalf("gqlclientgen failed: %v\n%v", err, out)
+			}
+			got, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+
+			golden := filepath.Join("internal", "golden", tc.name, "gql.go")
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				gotLines := strings.Split(string(got), "\n")
+				wantLines := strings.Split(string(want), "\n")
+				for i := 0; i < len(gotLines) && i < len(wantLines); i++ {
+					if gotLines[i] != wantLines[i] {
+						t.Fatalf("output differs from %v at line %v:\ngot:  %v\nwant: %v", golden, i+1, gotLines[i], wantLines[i])
+					}
+				}
+				t.Fatalf("output differs from %v: got %v lines, want %v", golden, len(gotLines), len(wantLines))
+			}
+		})
+	}
+}
+
+func TestMainErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "subscription",
+			args: []string{"-s", "testdata/schema.graphqls", "-q", "testdata/invalid/subscription.graphql"},
+			want: `subscription "onUserUpdated": subscriptions are not supported yet`,
+		},
+		{
+			name: "nested alias",
+			args: []string{"-s", "testdata/schema.graphqls", "-q", "testdata/queries/nodes.graphql"},
+			want: `nested field alias "displayName" requires per-operation types, pass -t`,
+		},
+		{
+			name: "interface conflict",
+			args: []string{"-s", "testdata/schema.graphqls", "-i", "Status"},
+			want: `client interface "Status": identifier "Status" conflicts with a generated type`,
+		},
+		{
+			name: "invalid binding",
+			args: []string{"-s", "testdata/schema.graphqls", "-B", "Account"},
+			want: `in binding "Account": expected <name>=<type>`,
+		},
+		{
+			name: "missing schema",
+			args: []string{"-s", "testdata/missing.graphqls"},
+			want: `no file matches "testdata/missing.graphqls"`,
+		},
+		{
+			name: "unknown config key",
+			args: []string{"-c", "testdata/invalid/unknown-key.json"},
+			want: `json: unknown field "interfce"`,
+		},
+		{
+			name: "output with multiple targets",
+			args: []string{"-c", "testdata/invalid/targets.json"},
+			want: "-o and -n cannot be used with multiple targets",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "gql.go")
+			out, err := runMain(t, append(tc.args, "-o", output)...)
+			if err == nil {
+				t.Fatalf("gqlclientgen succeeded, want an error")
+			}
+			if !strings.Contains(out, tc.want) {
+				t.Errorf("gqlclientgen = %q, want %q", out, tc.want)
+			}
+			if _, err := os.Stat(output); err == nil {
+				t.Errorf("output file written despite the error")
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"go/token"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultInitialisms is the list of initialisms written in upper case in Go
+// identifiers, as in golint.
+var defaultInitialisms = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP",
+	"HTTPS", "ID", "IP", "JSON", "LHS", "QPS", "RAM", "RHS", "RPC", "SLA",
+	"SMTP", "SQL", "SSH", "TCP", "TLS", "TTL", "UDP", "UI", "UID", "UUID",
+	"URI", "URL", "UTF8", "VM", "XML", "XMPP", "XSRF", "XSS",
+}
+
+// initialisms is the set of initialisms used by goName.
+var initialisms map[string]bool
+
+func setInitialisms(extra []string) {
+	initialisms = make(map[string]bool)
+	for _, l := range [][]string{defaultInitialisms, extra} {
+		for _, s := range l {
+			initialisms[strings.ToUpper(s)] = true
+		}
+	}
+}
+
+// splitWords splits a GraphQL name into words, e.g. "httpStatus",
+// "HTTPStatus" and "HTTP_STATUS" into "http"/"HTTP" and "Status"/"STATUS".
+// A lower case "s" ending an upper case word is kept with it, e.g. "imageURLs"
+// is split into "image" and "URLs".
+func splitWords(name string) []string {
+	var words []string
+	for _, part := range strings.Split(name, "_") {
+		runes := []rune(part)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			prev, cur := runes[i-1], runes[i]
+			split := false
+			if unicode.IsUpper(cur) && !unicode.IsUpper(prev) {
+				// "httpStatus"
+				split = true
+			} else if unicode.IsUpper(cur) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+				// "HTTPStatus", but not "URLs" nor "URLsList"
+				split = !isPluralSuffix(runes[i+1:])
+			}
+			if split {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			words = append(words, string(runes[start:]))
+		}
+	}
+	return words
+}
+
+// isPluralSuffix checks whether runes start with a lower case "s" ending a
+// word.
+func isPluralSuffix(runes []rune) bool {
+	return runes[0] == 's' && (len(runes) == 1 || !unicode.IsLower(runes[1]))
+}
+
+// goName converts a GraphQL name to an exported Go identifier, following Go
+// naming conventions, e.g. "userId" and "USER_ID" to "UserID", and "userIds"
+// to "UserIDs". Names which don't start with a letter once underscores are
+// removed are prefixed with "X", e.g. "_1st" becomes "X1st".
+func goName(name string) string {
+	var sb strings.Builder
+	for _, word := range splitWords(name) {
+		upper := strings.ToUpper(word)
+		if initialisms[upper] {
+			sb.WriteString(upper)
+			continue
+		}
+		if singular := strings.TrimSuffix(word, "s"); singular != word && initialisms[strings.ToUpper(singular)] {
+			// Plural initialism, e.g. "URLs"
+			sb.WriteString(strings.ToUpper(singular) + "s")
+			continue
+		}
+		if word == upper {
+			// Words in upper case, e.g. enum values
+			word = strings.ToLower(word)
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	name = sb.String()
+	if r, _ := utf8.DecodeRuneInString(name); !unicode.IsLetter(r) {
+		name = "X" + name
+	}
+	return name
+}
+
+// goVarName converts a GraphQL name to a Go variable name, escaping Go
+// keywords.
+func goVarName(name string) string {
+	if token.IsKeyword(name) {
+		return name + "_"
+	}
+	return name
+}
+
+// identSet allocates unique Go identifiers in a scope.
+type identSet map[string]bool
+
+// add allocates an identifier. If the name is already taken, underscores
+// are appended until it isn't.
+func (set identSet) add(name string) string {
+	for set[name] {
+		name += "_"
+	}
+	set[name] = true
+	return name
+}
+
+// declared contains the identifiers declared at the top level of the
+// generated file.
+var declared identSet
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "id", want: []string{"id"}},
+		{name: "httpStatus", want: []string{"http", "Status"}},
+		{name: "HTTPStatus", want: []string{"HTTP", "Status"}},
+		{name: "HTTP_STATUS", want: []string{"HTTP", "STATUS"}},
+		{name: "imageURLs", want: []string{"image", "URLs"}},
+		{name: "URLsList", want: []string{"URLs", "List"}},
+		{name: "APIs", want: []string{"APIs"}},
+		{name: "IDs", want: []string{"IDs"}},
+		{name: "userIds", want: []string{"user", "Ids"}},
+		{name: "sha256Sum", want: []string{"sha256", "Sum"}},
+		{name: "_1st", want: []string{"1st"}},
+		{name: "__typename", want: []string{"typename"}},
+		{name: "", want: nil},
+	}
+	for _, tc := range tests {
+		if got := splitWords(tc.name); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("splitWords(%q) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	setInitialisms([]string{"sku"})
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "id", want: "ID"},
+		{name: "userId", want: "UserID"},
+		{name: "USER_ID", want: "UserID"},
+		{name: "userIds", want: "UserIDs"},
+		{name: "userIDs", want: "UserIDs"},
+		{name: "IDs", want: "IDs"},
+		{name: "APIs", want: "APIs"},
+		{name: "apiUrl", want: "APIURL"},
+		{name: "imageURLs", want: "ImageURLs"},
+		{name: "URLsList", want: "URLsList"},
+		{name: "httpStatus", want: "HTTPStatus"},
+		{name: "HTTPStatus", want: "HTTPStatus"},
+		{name: "HTTP_STATUS", want: "HTTPStatus"},
+		{name: "PUBLIC", want: "Public"},
+		{name: "status", want: "Status"},
+		{name: "news", want: "News"},
+		{name: "skuCode", want: "SKUCode"},
+		{name: "type", want: "Type"},
+		{name: "func", want: "Func"},
+		{name: "_1st", want: "X1st"},
+		{name: "__typename", want: "Typename"},
+		{name: "_", want: "X"},
+		{name: "", want: "X"},
+		{name: "élan", want: "Élan"},
+	}
+	for _, tc := range tests {
+		if got := goName(tc.name); got != tc.want {
+			t.Errorf("goName(%q) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	setInitialisms(nil)
+	if got := goName("skuCode"); got != "SkuCode" {
+		t.Errorf("goName(%q) without extra initialisms = %q, want %q", "skuCode", got, "SkuCode")
+	}
+}
+
+func TestGoVarName(t *testing.T) {
+	for name, want := range map[string]string{
+		"id":    "id",
+		"type":  "type_",
+		"range": "range_",
+		"Type":  "Type",
+	} {
+		if got := goVarName(name); got != want {
+			t.Errorf("goVarName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestIdentSet(t *testing.T) {
+	set := make(identSet)
+	for _, want := range []string{"User", "User_", "User__"} {
+		if got := set.add("User"); got != want {
+			t.Errorf("add(%q) = %q, want %q", "User", got, want)
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// selectedField is a field of a selection set, grouped by response key.
+type selectedField struct {
+	key    string
+	fields []*ast.Field
+	// If true, all occurrences of the field are conditional, so it may be
+	// missing from the response
+	optional bool
+}
+
+// subSelectionSet merges the sub-selections of all occurrences of the field.
+func (sf *selectedField) subSelectionSet() ast.SelectionSet {
+	var selSet ast.SelectionSet
+	for _, field := range sf.fields {
+		selSet = append(selSet, field.SelectionSet...)
+	}
+	return selSet
+}
+
+// selection is the flattened content of a selection set.
+type selection struct {
+	fields []*selectedField
+	// Named fragments spread in the selection set, if they are not flattened
+	fragments []*ast.FragmentDefinition
+	// If true, the selection set contains fragments which only apply to some
+	// of the possible types. These are left out.
+	narrower bool
+}
+
+// collectSelection groups the fields selected on a type by response key,
+// flattening inline fragments. If embedFragments is true, named fragments
+// spread directly in the selection set are kept separate, otherwise they are
+// flattened. Conditional fragment spreads are always flattened.
+func collectSelection(schema *ast.Schema, def *ast.Definition, selSet ast.SelectionSet, embedFragments bool) *selection {
+	var sel selection
+	sel.add(schema, def, selSet, embedFragments, false)
+	return &sel
+}
+
+func (s *selection) add(schema *ast.Schema, def *ast.Definition, selSet ast.SelectionSet, embedFragments, conditional bool) {
+	for _, sel := range selSet {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			s.addField(sel, conditional || isConditional(sel.Directives))
+		case *ast.FragmentSpread:
+			applies, narrower := fragmentScope(schema, def, sel.Definition.TypeCondition)
+			if !applies {
+				continue
+			}
+			cond := conditional || isConditional(sel.Directives)
+			if narrower {
+				s.narrower = true
+			} else if embedFragments && !cond {
+				s.addFragment(sel.Definition)
+			} else {
+				s.add(schema, def, sel.Definition.SelectionSet, false, cond)
+			}
+		case *ast.InlineFragment:
+			applies, narrower := fragmentScope(schema, def, sel.TypeCondition)
+			if narrower {
+				s.narrower = true
+			} else if applies {
+				s.add(schema, def, sel.SelectionSet, false, conditional || isConditional(sel.Directives))
+			}
+		default:
+			panic(fmt.Sprintf("unsupported selection type: %T", sel))
+		}
+	}
+}
+
+func (s *selection) addField(field *ast.Field, conditional bool) {
+	key := field.Alias
+	if key == "" {
+		key = field.Name
+	}
+	for _, sf := range s.fields {
+		if sf.key == key {
+			sf.fields = append(sf.fields, field)
+			sf.optional = sf.optional && conditional
+			return
+		}
+	}
+	s.fields = append(s.fields, &selectedField{
+		key:      key,
+		fields:   []*ast.Field{field},
+		optional: conditional,
+	})
+}
+
+// isConditional checks whether a selection has @skip or @include directives.
+func isConditional(directives ast.DirectiveList) bool {
+	return directives.ForName("skip") != nil || directives.ForName("include") != nil
+}
+
+// optionalType returns a nullable copy of a type.
+func optionalType(t *ast.Type) *ast.Type {
+	nullable := *t
+	nullable.NonNull = false
+	return &nullable
+}
+
+func (s *selection) addFragment(frag *ast.FragmentDefinition) {
+	for _, f := range s.fragments {
+		if f == frag {
+			return
+		}
+	}
+	s.fragments = append(s.fragments, frag)
+}
+
+// fragmentScope checks whether a fragment with the specified type condition
+// applies to a type, and if so whether it only applies to some of its
+// possible types.
+func fragmentScope(schema *ast.Schema, def *ast.Definition, typeCond string) (applies, narrower bool) {
+	if typeCond == "" || typeCond == def.Name {
+		return true, false
+	}
+	cond, ok := schema.Types[typeCond]
+	if !ok {
+		panic(fmt.Sprintf("unknown type name %q", typeCond))
+	}
+
+	condTypes := make(map[string]bool)
+	for _, typ := range schema.GetPossibleTypes(cond) {
+		condTypes[typ.Name] = true
+	}
+	possibleTypes := schema.GetPossibleTypes(def)
+	n := 0
+	for _, typ := range possibleTypes {
+		if condTypes[typ.Name] {
+			n++
+		}
+	}
+	return n > 0, n > 0 && n < len(possibleTypes)
+}
+
+// selectionGen generates Go types containing exactly the fields selected by
+// operations. Nested types are named after their path in the operation, e.g.
+// "FetchMeMeFriends". Named fragments are generated as separate types named
+// after the fragment, and embedded where they are spread. Fragments with type
+// conditions narrower than the selection set type result in per-type
+// variants.
+type selectionGen struct {
+	schema *ast.Schema
+	// Generated definitions, flushed by the caller
+	defs []jen.Code
+	// Generated fragment types, indexed by fragment name
+	fragments map[string]fragmentType
+}
+
+type fragmentType struct {
+	def    *ast.FragmentDefinition
+	goName string
+}
+
+// variantType is a struct type for a possible type of an abstract type.
+type variantType struct {
+	typeName, goName string
+}
+
+func newSelectionGen(schema *ast.Schema) *selectionGen {
+	return &selectionGen{
+		schema:    schema,
+		fragments: make(map[string]fragmentType),
+	}
+}
+
+// genFragment generates a struct type for a named fragment, if not already
+// done, and returns its name.
+func (g *selectionGen) genFragment(frag *ast.FragmentDefinition) string {
+	if prev, ok := g.fragments[frag.Name]; ok {
+		if prev.def != frag {
+			panic(fmt.Sprintf("fragment %q is defined multiple times", frag.Name))
+		}
+		return prev.goName
+	}
+	name := declared.add(goName(frag.Name))
+	g.fragments[frag.Name] = fragmentType{def: frag, goName: name}
+
+	def, ok := g.schema.Types[frag.TypeCondition]
+	if !ok {
+		panic(fmt.Sprintf("unknown type name %q", frag.TypeCondition))
+	}
+	g.genStruct(name, def, frag.SelectionSet)
+	return name
+}
+
+// genStruct generates a struct type for a selection set on a type.
+//
+// If the type is abstract and some fragments only apply to some of its
+// possible types, a variant struct is generated for each possible type, with
+// all the fields selected for it. The variant is stored in the Value field.
+func (g *selectionGen) genStruct(name string, def *ast.Definition, selSet ast.SelectionSet) {
+	// Reserve a slot so that types are defined before their nested types
+	i := len(g.defs)
+	g.defs = append(g.defs, nil)
+
+	sel := collectSelection(g.schema, def, selSet, true)
+
+	fieldNames := make(identSet)
+	if sel.narrower {
+		fieldNames.add("Value")
+	}
+
+	var fields []jen.Code
+	var fragNames []string
+	for _, frag := range sel.fragments {
+		fragName := g.genFragment(frag)
+		fieldNames.add(fragName)
+		fragNames = append(fragNames, fragName)
+		// Embedded fragments are decoded separately, because several of them
+		// may contain the same fields
+		fields = append(fields, jen.Id(fragName).Tag(map[string]string{"json": "-"}))
+	}
+	if len(fields) > 0 && len(sel.fields) > 0 {
+		fields = append(fields, jen.Line())
+	}
+
+	var fieldPtrs, fieldPtrInits []jen.Code
+	for _, sf := range sel.fields {
+		var desc jen.Code = jen.Null()
+		if def := sf.fields[0].Definition; def != nil {
+			desc = genDescription(def.Description)
+		}
+		fieldName := fieldNames.add(goName(sf.key))
+		typ := g.genFieldType(name, sf)
+		tag := jen.Tag(map[string]string{"json": sf.key})
+		fields = append(fields, jen.Add(desc).Id(fieldName).Add(typ).Add(tag))
+		fieldPtrs = append(fieldPtrs, jen.Id(fieldName).Op("*").Add(typ).Add(tag))
+		fieldPtrInits = append(fieldPtrInits, jen.Id("fields").Dot(fieldName).Op("=").Op("&").Id("v").Dot(fieldName))
+	}
+
+	var variants []variantType
+	var valueName string
+	if sel.narrower {
+		valueName = declared.add(name + "Value")
+		if len(fields) > 0 {
+			fields = append(fields, jen.Line())
+		}
+		fields = append(fields,
+			jen.Comment("Underlying value, depending on the concrete type"),
+			jen.Id("Value").Id(valueName).Tag(map[string]string{"json": "-"}),
+		)
+		for _, typ := range g.schema.GetPossibleTypes(def) {
+			variant := variantType{typeName: typ.Name, goName: declared.add(name + goTypeName(typ.Name))}
+			g.genStruct(variant.goName, typ, selSet)
+			variants = append(variants, variant)
+		}
+	}
+
+	stmt := jen.Type().Id(name).Struct(fields...)
+	if len(fragNames) > 0 || len(variants) > 0 {
+		stmt.Line().Line().Add(genUnmarshal(name, fieldPtrs, fieldPtrInits, fragNames, variants))
+	}
+	if len(variants) > 0 {
+		var variantNames []string
+		for _, variant := range variants {
+			variantNames = append(variantNames, "*"+variant.goName)
+		}
+		stmt.Line().Line().Comment(valueName + " is one of: " + strings.Join(variantNames, " | "))
+		stmt.Line().Type().Id(valueName).Interface(jen.Id("is" + name).Params())
+		for _, variant := range variants {
+			stmt.Line().Line().Func().Params(jen.Op("*").Id(variant.goName)).Id("is" + name).Params().Block()
+		}
+	}
+	g.defs[i] = stmt
+}
+
+// genUnmarshal generates an UnmarshalJSON method for a struct with embedded
+// fragments or variants, decoding each of them from the same object.
+func genUnmarshal(name string, fieldPtrs, fieldPtrInits []jen.Code, fragNames []string, variants []variantType) jen.Code {
+	var stmts []jen.Code
+	if len(fieldPtrs) > 0 {
+		stmts = append(stmts, jen.Var().Id("fields").Struct(fieldPtrs...))
+		stmts = append(stmts, fieldPtrInits...)
+		stmts = append(stmts, jen.If(
+			jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("fields")),
+			jen.Err().Op("!=").Nil(),
+		).Block(jen.Return(jen.Err())))
+	}
+	for _, fragName := range fragNames {
+		stmts = append(stmts, jen.If(
+			jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("v").Dot(fragName)),
+			jen.Err().Op("!=").Nil(),
+		).Block(jen.Return(jen.Err())))
+	}
+
+	if len(variants) == 0 {
+		stmts = append(stmts, jen.Return(jen.Nil()))
+	} else {
+		var cases []jen.Code
+		for _, variant := range variants {
+			cases = append(cases, jen.Case(jen.Lit(variant.typeName)).Block(
+				jen.Id("v").Dot("Value").Op("=").New(jen.Id(variant.goName)),
+			))
+		}
+		cases = append(cases, jen.Default().Block(
+			jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("gqlclient: "+name+": unknown __typename %q"), jen.Id("data").Dot("TypeName"))),
+		))
+		stmts = append(stmts,
+			jen.Var().Id("data").Struct(
+				jen.Id("TypeName").String().Tag(map[string]string{"json": "__typename"}),
+			),
+			jen.If(
+				jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Op("&").Id("data")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Err())),
+			jen.Switch(jen.Id("data").Dot("TypeName")).Block(cases...),
+			jen.Return(jen.Qual("encoding/json", "Unmarshal").Call(jen.Id("b"), jen.Id("v").Dot("Value"))),
+		)
+	}
+
+	return jen.Func().Params(jen.Id("v").Op("*").Id(name)).Id("UnmarshalJSON").Params(jen.Id("b").Index().Byte()).Error().Block(stmts...)
+}
+
+// genFieldType returns the Go type of a selected field, generating nested
+// types as needed.
+func (g *selectionGen) genFieldType(parentName string, sf *selectedField) jen.Code {
+	field := sf.fields[0]
+	if field.Name == "__typename" {
+		return jen.String()
+	}
+
+	t := field.Definition.Type
+	def, ok := g.schema.Types[t.Name()]
+	if !ok {
+		panic(fmt.Sprintf("unknown type name %q", t.Name()))
+	}
+
+	switch def.Kind {
+	case ast.Object, ast.Interface, ast.Union:
+		if !isBound(def.Name) {
+			break
+		}
+		fallthrough
+	default:
+		if sf.optional {
+			return genOptionalType(g.schema, t)
+		}
+		return genType(g.schema, t)
+	}
+
+	if sf.optional {
+		t = optionalType(t)
+	}
+
+	name := declared.add(parentName + goName(sf.key))
+	g.genStruct(name, def, sf.subSelectionSet())
+
+	var prefix []jen.Code
+	for t.Elem != nil {
+		prefix = append(prefix, jen.Index())
+		t = t.Elem
+	}
+	if !t.NonNull {
+		prefix = append(prefix, jen.Op("*"))
+	}
+	return jen.Add(prefix...).Id(name)
+}
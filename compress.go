@@ -0,0 +1,125 @@
+package gqlclient
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Encoding is an HTTP content coding, used to compress request and response
+// bodies.
+//
+// Gzip, Zstd and Brotli are provided by this package. Other codings can be
+// plugged in by wrapping third-party implementations.
+type Encoding struct {
+	// Name of the content coding, as used in the Content-Encoding and
+	// Accept-Encoding header fields.
+	Name string
+	// NewWriter returns a writer compressing data written to it into w.
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+	// NewReader returns a reader decompressing data read from r.
+	NewReader func(r io.Reader) (io.ReadCloser, error)
+}
+
+// Gzip is the gzip content coding.
+var Gzip = &Encoding{
+	Name: "gzip",
+	NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	},
+	NewReader: func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+}
+
+// Zstd is the Zstandard content coding.
+var Zstd = &Encoding{
+	Name: "zstd",
+	NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+		return zstd.NewWriter(w)
+	},
+	NewReader: func(r io.Reader) (io.ReadCloser, error) {
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return dec.IOReadCloser(), nil
+	},
+}
+
+// Brotli is the Brotli content coding.
+var Brotli = &Encoding{
+	Name: "br",
+	NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+		return brotli.NewWriter(w), nil
+	},
+	NewReader: func(r io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(brotli.NewReader(r)), nil
+	},
+}
+
+func compressBody(enc *Encoding, w io.Writer, r io.Reader) error {
+	cw, err := enc.NewWriter(w)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(cw, r); err != nil {
+		cw.Close()
+		return err
+	}
+	return cw.Close()
+}
+
+func acceptEncoding(encs []*Encoding) string {
+	l := make([]string, len(encs))
+	for i, enc := range encs {
+		l[i] = enc.Name
+	}
+	return strings.Join(l, ", ")
+}
+
+// Decompress returns a reader undoing the content codings listed in the value
+// of a Content-Encoding header field, using the specified encodings. Codings
+// are listed in the order they were applied, so they are undone in reverse
+// order. An empty value or "identity" leaves the body as-is.
+//
+// The caller is responsible for closing the returned reader.
+func Decompress(encs []*Encoding, contentEncoding string, r io.Reader) (io.ReadCloser, error) {
+	var codings []*Encoding
+	for _, name := range strings.Split(contentEncoding, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || strings.EqualFold(name, "identity") {
+			continue
+		}
+		enc := findEncoding(encs, name)
+		if enc == nil {
+			return nil, fmt.Errorf("unsupported Content-Encoding %q", contentEncoding)
+		}
+		codings = append(codings, enc)
+	}
+
+	var closers multiCloser
+	for i := len(codings) - 1; i >= 0; i-- {
+		rc, err := codings[i].NewReader(r)
+		if err != nil {
+			closers.Close()
+			return nil, err
+		}
+		closers = append(closers, rc)
+		r = rc
+	}
+	return &responseBody{Reader: r, closers: closers}, nil
+}
+
+func findEncoding(encs []*Encoding, name string) *Encoding {
+	for _, enc := range encs {
+		if strings.EqualFold(enc.Name, name) {
+			return enc
+		}
+	}
+	return nil
+}
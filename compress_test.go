@@ -0,0 +1,115 @@
+package gqlclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func compress(t *testing.T, enc *Encoding, s string) []byte {
+	var buf bytes.Buffer
+	if err := compressBody(enc, &buf, strings.NewReader(s)); err != nil {
+		t.Fatalf("compressBody(%v) = %v", enc.Name, err)
+	}
+	return buf.Bytes()
+}
+
+func TestEncodingRoundTrip(t *testing.T) {
+	const s = `{"data":{"hello":"world"}}`
+	for _, enc := range []*Encoding{Gzip, Zstd, Brotli} {
+		rc, err := Decompress([]*Encoding{enc}, enc.Name, bytes.NewReader(compress(t, enc, s)))
+		if err != nil {
+			t.Fatalf("Decompress(%v) = %v", enc.Name, err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("failed to read %v body: %v", enc.Name, err)
+		} else if string(b) != s {
+			t.Errorf("%v round-trip = %q, want %q", enc.Name, b, s)
+		}
+	}
+}
+
+func TestDecompressBodyStacked(t *testing.T) {
+	const s = "hello"
+	// gzip applied first, then br
+	b := compress(t, Gzip, s)
+	var buf bytes.Buffer
+	if err := compressBody(Brotli, &buf, bytes.NewReader(b)); err != nil {
+		t.Fatal(err)
+	}
+
+	encs := []*Encoding{Gzip, Brotli}
+	for _, contentEncoding := range []string{"gzip, br", "gzip, identity, br", " GZIP ,br"} {
+		rc, err := Decompress(encs, contentEncoding, bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("Decompress(%q) = %v", contentEncoding, err)
+		}
+		got, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil || string(got) != s {
+			t.Errorf("Decompress(%q) = %q, %v, want %q", contentEncoding, got, err, s)
+		}
+	}
+}
+
+func TestDecompressBodyUnsupported(t *testing.T) {
+	for _, contentEncoding := range []string{"zstd", "gzip, zstd"} {
+		if _, err := Decompress([]*Encoding{Gzip}, contentEncoding, strings.NewReader("")); err == nil {
+			t.Errorf("Decompress(%q) succeeded", contentEncoding)
+		}
+	}
+	for _, contentEncoding := range []string{"", "identity"} {
+		rc, err := Decompress(nil, contentEncoding, strings.NewReader("hello"))
+		if err != nil {
+			t.Fatalf("Decompress(%q) = %v", contentEncoding, err)
+		}
+		if b, _ := io.ReadAll(rc); string(b) != "hello" {
+			t.Errorf("Decompress(%q) = %q, want %q", contentEncoding, b, "hello")
+		}
+	}
+}
+
+func TestClientCompression(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if got := req.Header.Get("Content-Encoding"); got != "zstd" {
+			t.Errorf("request Content-Encoding = %q, want %q", got, "zstd")
+		}
+		if got := req.Header.Get("Accept-Encoding"); got != "br, gzip" {
+			t.Errorf("request Accept-Encoding = %q, want %q", got, "br, gzip")
+		}
+		rc, err := Zstd.NewReader(req.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil || !strings.Contains(string(b), "hello") {
+			t.Errorf("request body = %q, %v", b, err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "br")
+		w.Write(compress(t, Brotli, `{"data":{"hello":"world"}}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, nil)
+	c.RequestEncoding = Zstd
+	c.ResponseEncodings = []*Encoding{Brotli, Gzip}
+
+	var data struct {
+		Hello string
+	}
+	if err := c.Execute(context.Background(), NewOperation("query { hello }"), &data); err != nil {
+		t.Fatalf("Execute() = %v", err)
+	}
+	if data.Hello != "world" {
+		t.Errorf("hello = %q, want %q", data.Hello, "world")
+	}
+}
@@ -2,6 +2,7 @@ package gqlclient
 
 import (
 	"encoding/json"
+	"fmt"
 )
 
 // ErrorLocation describes an error location in a GraphQL document.
@@ -41,3 +42,17 @@ func (err *HTTPError) Error() string {
 func (err *HTTPError) Unwrap() error {
 	return err.err
 }
+
+// LimitError is returned when a response exceeds one of the limits configured
+// on a Client.
+type LimitError struct {
+	// Name of the exceeded limit: "MaxResponseSize", "MaxResponseDepth" or
+	// "MaxResponseErrors"
+	Limit string
+	// Configured maximum value
+	Max int64
+}
+
+func (err *LimitError) Error() string {
+	return fmt.Sprintf("gqlclient: response exceeds %v (%v)", err.Limit, err.Max)
+}
@@ -2,7 +2,9 @@ package gqlclient_test
 
 import (
 	"context"
+	"encoding/json"
 	"log"
+	"net/url"
 	"strings"
 
 	"git.sr.ht/~emersion/gqlclient"
@@ -72,3 +74,139 @@ func ExampleClient_Execute_upload() {
 		log.Fatal(err)
 	}
 }
+
+func ExampleNewBalanced() {
+	var ctx context.Context
+
+	c := gqlclient.NewBalanced([]string{
+		"https://gateway1.example.org/query",
+		"https://gateway2.example.org/query",
+	}, nil, &gqlclient.BalanceOptions{
+		Policy: gqlclient.BalanceLeastLatency,
+	})
+
+	op := gqlclient.NewOperation(`query {
+		me {
+			name
+		}
+	}`)
+
+	var data struct {
+		Me struct {
+			Name string
+		}
+	}
+	if err := c.Execute(ctx, op, &data); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Print(data)
+}
+
+func ExampleClient_ExecuteStream() {
+	var ctx context.Context
+	var c *gqlclient.Client
+
+	op := gqlclient.NewOperation(`query {
+		orders {
+			edges {
+				node {
+					id
+				}
+			}
+		}
+	}`)
+
+	s, err := c.ExecuteStream(ctx, op, "orders.edges")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer s.Close()
+
+	var edge struct {
+		Node struct {
+			ID string
+		}
+	}
+	for s.Next(&edge) {
+		log.Print(edge.Node.ID)
+	}
+	if err := s.Err(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ExampleClient_Paginate() {
+	var ctx context.Context
+	var c *gqlclient.Client
+
+	op := gqlclient.NewOperation(`query ($after: String) {
+		me {
+			repositories(first: 50, after: $after) {
+				edges {
+					node {
+						name
+					}
+				}
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
+			}
+		}
+	}`)
+
+	p := c.Paginate(ctx, op, "me.repositories", nil)
+
+	var repo struct {
+		Name string
+	}
+	for p.Next(&repo) {
+		log.Print(repo.Name)
+	}
+	if err := p.Err(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ExampleRegisterScalar() {
+	// Codec for the URL scalar, used by code generated with
+	// "gqlclientgen -C URL=net/url.URL"
+	gqlclient.RegisterScalar("URL", &gqlclient.Scalar{
+		GoType: "net/url.URL",
+		Marshal: func(v interface{}) ([]byte, error) {
+			return json.Marshal(v.(*url.URL).String())
+		},
+		Unmarshal: func(b []byte, v interface{}) error {
+			var s string
+			if err := json.Unmarshal(b, &s); err != nil {
+				return err
+			}
+			u, err := url.Parse(s)
+			if err != nil {
+				return err
+			}
+			*v.(*url.URL) = *u
+			return nil
+		},
+	})
+}
+
+func ExampleClient_ExecuteQuery() {
+	var ctx context.Context
+	var c *gqlclient.Client
+
+	var data struct {
+		User struct {
+			Age int
+		} `graphql:"user(username: $name)"`
+	}
+	err := c.ExecuteQuery(ctx, &data, map[string]interface{}{
+		"name": "emersion",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Print(data)
+}
@@ -1,9 +1,11 @@
 module git.sr.ht/~emersion/gqlclient
 
-go 1.17
+go 1.18
 
 require (
+	github.com/andybalholm/brotli v1.1.0
 	github.com/dave/jennifer v1.7.0
+	github.com/klauspost/compress v1.16.7
 	github.com/vektah/gqlparser/v2 v2.5.8
 )
 
@@ -0,0 +1,460 @@
+// Package gqlbuilder builds GraphQL query documents programmatically.
+//
+// It can be used to construct queries at runtime, e.g. when the selected
+// fields or filters depend on user input, without concatenating strings.
+// Names are validated and string literals are escaped.
+package gqlbuilder
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"git.sr.ht/~emersion/gqlclient"
+)
+
+var (
+	nameRegexp = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+	typeRegexp = regexp.MustCompile(`^(\[*)[_A-Za-z][_0-9A-Za-z]*!?((?:\]!?)*)$`)
+)
+
+func checkName(kind, name string) error {
+	if !nameRegexp.MatchString(name) {
+		return fmt.Errorf("gqlbuilder: invalid %v name %q", kind, name)
+	}
+	return nil
+}
+
+func checkType(typ string) error {
+	m := typeRegexp.FindStringSubmatch(typ)
+	if m == nil || len(m[1]) != strings.Count(m[2], "]") {
+		return fmt.Errorf("gqlbuilder: invalid type %q", typ)
+	}
+	return nil
+}
+
+// Selection is a field, a fragment spread or an inline fragment.
+type Selection interface {
+	writeTo(w *writer)
+}
+
+// Variable is a variable defined on an operation. It can be used as an
+// argument value.
+type Variable struct {
+	name  string
+	typ   string
+	value interface{}
+	set   bool
+}
+
+// Enum is an enum value, used as an argument value.
+type Enum string
+
+// Field is a field selection.
+type Field struct {
+	alias string
+	name  string
+	args  []argument
+	sels  []Selection
+	errs  []error
+}
+
+type argument struct {
+	name  string
+	value interface{}
+}
+
+// NewField creates a new field selection.
+func NewField(name string) *Field {
+	f := &Field{name: name}
+	if err := checkName("field", name); err != nil {
+		f.errs = append(f.errs, err)
+	}
+	return f
+}
+
+// As sets the alias of the field.
+func (f *Field) As(alias string) *Field {
+	if err := checkName("alias", alias); err != nil {
+		f.errs = append(f.errs, err)
+	}
+	f.alias = alias
+	return f
+}
+
+// Arg adds an argument to the field.
+//
+// The value can be a *Variable, an Enum, nil, a boolean, a number, a string,
+// a slice or a map with string keys.
+func (f *Field) Arg(name string, value interface{}) *Field {
+	if err := checkName("argument", name); err != nil {
+		f.errs = append(f.errs, err)
+	}
+	f.args = append(f.args, argument{name, value})
+	return f
+}
+
+// Select adds sub-selections to the field.
+func (f *Field) Select(sels ...Selection) *Field {
+	f.sels = append(f.sels, sels...)
+	return f
+}
+
+func (f *Field) writeTo(w *writer) {
+	w.errs = append(w.errs, f.errs...)
+
+	w.indent()
+	if f.alias != "" {
+		w.WriteString(f.alias + ": ")
+	}
+	w.WriteString(f.name)
+	if len(f.args) > 0 {
+		w.WriteString("(")
+		for i, arg := range f.args {
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			w.WriteString(arg.name + ": ")
+			w.writeValue(arg.value)
+		}
+		w.WriteString(")")
+	}
+	if len(f.sels) > 0 {
+		w.WriteString(" ")
+		w.writeSelectionSet(f.sels)
+	}
+	w.WriteString("\n")
+}
+
+// Fragment is a named fragment definition.
+type Fragment struct {
+	name     string
+	typeCond string
+	sels     []Selection
+	errs     []error
+}
+
+// NewFragment creates a new named fragment on the specified type.
+func NewFragment(name, typeCond string) *Fragment {
+	frag := &Fragment{name: name, typeCond: typeCond}
+	if err := checkName("fragment", name); err != nil {
+		frag.errs = append(frag.errs, err)
+	}
+	if err := checkName("type", typeCond); err != nil {
+		frag.errs = append(frag.errs, err)
+	}
+	return frag
+}
+
+// Select adds selections to the fragment.
+func (frag *Fragment) Select(sels ...Selection) *Fragment {
+	frag.sels = append(frag.sels, sels...)
+	return frag
+}
+
+type fragmentSpread struct {
+	frag *Fragment
+}
+
+// Spread returns a fragment spread selection. The fragment definition is
+// automatically included in the document.
+func Spread(frag *Fragment) Selection {
+	return &fragmentSpread{frag}
+}
+
+func (spread *fragmentSpread) writeTo(w *writer) {
+	w.useFragment(spread.frag)
+	w.indent()
+	w.WriteString("..." + spread.frag.name + "\n")
+}
+
+type inlineFragment struct {
+	typeCond string
+	sels     []Selection
+}
+
+// On returns an inline fragment selection with a type condition.
+func On(typeCond string, sels ...Selection) Selection {
+	return &inlineFragment{typeCond, sels}
+}
+
+func (frag *inlineFragment) writeTo(w *writer) {
+	if err := checkName("type", frag.typeCond); err != nil {
+		w.errs = append(w.errs, err)
+	}
+	w.indent()
+	w.WriteString("... on " + frag.typeCond + " ")
+	w.writeSelectionSet(frag.sels)
+	w.WriteString("\n")
+}
+
+// Operation is a GraphQL operation under construction.
+type Operation struct {
+	typ  string
+	name string
+	vars []*Variable
+	sels []Selection
+	errs []error
+}
+
+func newOperation(typ, name string) *Operation {
+	op := &Operation{typ: typ, name: name}
+	if name != "" {
+		if err := checkName("operation", name); err != nil {
+			op.errs = append(op.errs, err)
+		}
+	}
+	return op
+}
+
+// Query creates a new query operation. The name is optional.
+func Query(name string) *Operation {
+	return newOperation("query", name)
+}
+
+// Mutation creates a new mutation operation. The name is optional.
+func Mutation(name string) *Operation {
+	return newOperation("mutation", name)
+}
+
+// Var defines a variable with the specified GraphQL type (e.g. "[ID!]!") and
+// value. The returned variable can be used as an argument value.
+func (op *Operation) Var(name, typ string, value interface{}) *Variable {
+	if err := checkName("variable", name); err != nil {
+		op.errs = append(op.errs, err)
+	}
+	if err := checkType(typ); err != nil {
+		op.errs = append(op.errs, err)
+	}
+	for _, v := range op.vars {
+		if v.name == name {
+			op.errs = append(op.errs, fmt.Errorf("gqlbuilder: variable %q defined twice", name))
+		}
+	}
+	v := &Variable{name: name, typ: typ, value: value, set: true}
+	op.vars = append(op.vars, v)
+	return v
+}
+
+// OptionalVar defines a variable without a value.
+func (op *Operation) OptionalVar(name, typ string) *Variable {
+	v := op.Var(name, typ, nil)
+	v.set = false
+	return v
+}
+
+// Select adds selections to the operation.
+func (op *Operation) Select(sels ...Selection) *Operation {
+	op.sels = append(op.sels, sels...)
+	return op
+}
+
+// Render renders the query document. It fails if the operation is invalid.
+func (op *Operation) Render() (string, error) {
+	w := &writer{fragments: make(map[*Fragment]bool)}
+	w.errs = append(w.errs, op.errs...)
+
+	w.WriteString(op.typ)
+	if op.name != "" {
+		w.WriteString(" " + op.name)
+	}
+	if len(op.vars) > 0 {
+		w.WriteString("(")
+		for i, v := range op.vars {
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			w.WriteString("$" + v.name + ": " + v.typ)
+		}
+		w.WriteString(")")
+	}
+	w.WriteString(" ")
+	w.writeSelectionSet(op.sels)
+	w.WriteString("\n")
+
+	// Fragments may spread other fragments
+	for i := 0; i < len(w.fragList); i++ {
+		frag := w.fragList[i]
+		w.errs = append(w.errs, frag.errs...)
+		w.WriteString("\nfragment " + frag.name + " on " + frag.typeCond + " ")
+		w.writeSelectionSet(frag.sels)
+		w.WriteString("\n")
+	}
+
+	if len(w.errs) > 0 {
+		return "", w.errs[0]
+	}
+	return w.String(), nil
+}
+
+// Build renders the query document and returns a GraphQL operation with all
+// variable values set.
+func (op *Operation) Build() (*gqlclient.Operation, error) {
+	query, err := op.Render()
+	if err != nil {
+		return nil, err
+	}
+	gqlOp := gqlclient.NewOperation(query)
+	for _, v := range op.vars {
+		if v.set {
+			gqlOp.Var(v.name, v.value)
+		}
+	}
+	return gqlOp, nil
+}
+
+type writer struct {
+	strings.Builder
+	depth     int
+	errs      []error
+	fragments map[*Fragment]bool
+	fragList  []*Fragment
+}
+
+func (w *writer) indent() {
+	w.WriteString(strings.Repeat("\t", w.depth))
+}
+
+func (w *writer) useFragment(frag *Fragment) {
+	if w.fragments[frag] {
+		return
+	}
+	for other := range w.fragments {
+		if other.name == frag.name {
+			w.errs = append(w.errs, fmt.Errorf("gqlbuilder: fragment %q defined twice", frag.name))
+		}
+	}
+	w.fragments[frag] = true
+	w.fragList = append(w.fragList, frag)
+}
+
+func (w *writer) writeSelectionSet(sels []Selection) {
+	if len(sels) == 0 {
+		w.errs = append(w.errs, fmt.Errorf("gqlbuilder: empty selection set"))
+	}
+	w.WriteString("{\n")
+	w.depth++
+	for _, sel := range sels {
+		sel.writeTo(w)
+	}
+	w.depth--
+	w.indent()
+	w.WriteString("}")
+}
+
+func (w *writer) writeValue(v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		w.WriteString("null")
+		return
+	case *Variable:
+		w.WriteString("$" + v.name)
+		return
+	case Enum:
+		if err := checkName("enum value", string(v)); err != nil {
+			w.errs = append(w.errs, err)
+		}
+		w.WriteString(string(v))
+		return
+	case string:
+		w.WriteString(quoteString(v))
+		return
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			w.WriteString("null")
+		} else {
+			w.writeValue(rv.Elem().Interface())
+		}
+	case reflect.Bool:
+		w.WriteString(strconv.FormatBool(rv.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		w.WriteString(strconv.FormatInt(rv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		w.WriteString(strconv.FormatUint(rv.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			w.errs = append(w.errs, fmt.Errorf("gqlbuilder: invalid float value %v", f))
+		}
+		s := strconv.FormatFloat(f, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eE") {
+			s += ".0"
+		}
+		w.WriteString(s)
+	case reflect.String:
+		w.WriteString(quoteString(rv.String()))
+	case reflect.Slice, reflect.Array:
+		w.WriteString("[")
+		for i := 0; i < rv.Len(); i++ {
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			w.writeValue(rv.Index(i).Interface())
+		}
+		w.WriteString("]")
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			w.errs = append(w.errs, fmt.Errorf("gqlbuilder: unsupported map key type %v", rv.Type().Key()))
+			return
+		}
+		keys := make([]string, 0, rv.Len())
+		for _, k := range rv.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		w.WriteString("{")
+		for i, k := range keys {
+			if err := checkName("object field", k); err != nil {
+				w.errs = append(w.errs, err)
+			}
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			w.WriteString(k + ": ")
+			w.writeValue(rv.MapIndex(reflect.ValueOf(k).Convert(rv.Type().Key())).Interface())
+		}
+		w.WriteString("}")
+	default:
+		w.errs = append(w.errs, fmt.Errorf("gqlbuilder: unsupported value type %T", v))
+	}
+}
+
+// quoteString formats a GraphQL string literal.
+func quoteString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7F {
+				fmt.Fprintf(&sb, `\u%04X`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
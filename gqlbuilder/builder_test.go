@@ -0,0 +1,316 @@
+package gqlbuilder
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~emersion/gqlclient"
+)
+
+func TestRenderErrors(t *testing.T) {
+	type color string
+
+	tests := []struct {
+		name string
+		op   func() *Operation
+		want string
+	}{
+		{
+			name: "operation name",
+			op: func() *Operation {
+				return Query("fetch users").Select(NewField("me"))
+			},
+			want: `invalid operation name "fetch users"`,
+		},
+		{
+			name: "field name",
+			op: func() *Operation {
+				return Query("").Select(NewField("1st"))
+			},
+			want: `invalid field name "1st"`,
+		},
+		{
+			name: "alias",
+			op: func() *Operation {
+				return Query("").Select(NewField("me").As("my-self"))
+			},
+			want: `invalid alias name "my-self"`,
+		},
+		{
+			name: "argument name",
+			op: func() *Operation {
+				return Query("").Select(NewField("user").Arg("", 1))
+			},
+			want: `invalid argument name ""`,
+		},
+		{
+			name: "variable name",
+			op: func() *Operation {
+				op := Query("")
+				op.Var("$id", "ID!", "1")
+				return op.Select(NewField("me"))
+			},
+			want: `invalid variable name "$id"`,
+		},
+		{
+			name: "unbalanced list type",
+			op: func() *Operation {
+				op := Query("")
+				op.Var("ids", "[ID!", nil)
+				return op.Select(NewField("me"))
+			},
+			want: `invalid type "[ID!"`,
+		},
+		{
+			name: "extra list bracket",
+			op: func() *Operation {
+				op := Query("")
+				op.Var("ids", "[ID]]", nil)
+				return op.Select(NewField("me"))
+			},
+			want: `invalid type "[ID]]"`,
+		},
+		{
+			name: "double non-null",
+			op: func() *Operation {
+				op := Query("")
+				op.OptionalVar("id", "ID!!")
+				return op.Select(NewField("me"))
+			},
+			want: `invalid type "ID!!"`,
+		},
+		{
+			name: "duplicate variable",
+			op: func() *Operation {
+				op := Query("")
+				op.Var("id", "ID!", "1")
+				op.Var("id", "ID!", "2")
+				return op.Select(NewField("me"))
+			},
+			want: `variable "id" defined twice`,
+		},
+		{
+			name: "fragment name",
+			op: func() *Operation {
+				frag := NewFragment("User Fields", "User").Select(NewField("name"))
+				return Query("").Select(NewField("me").Select(Spread(frag)))
+			},
+			want: `invalid fragment name "User Fields"`,
+		},
+		{
+			name: "fragment type",
+			op: func() *Operation {
+				frag := NewFragment("UserFields", "").Select(NewField("name"))
+				return Query("").Select(NewField("me").Select(Spread(frag)))
+			},
+			want: `invalid type name ""`,
+		},
+		{
+			name: "inline fragment type",
+			op: func() *Operation {
+				return Query("").Select(NewField("me").Select(On("[User]", NewField("name"))))
+			},
+			want: `invalid type name "[User]"`,
+		},
+		{
+			name: "duplicate fragment",
+			op: func() *Operation {
+				a := NewFragment("UserFields", "User").Select(NewField("name"))
+				b := NewFragment("UserFields", "User").Select(NewField("age"))
+				return Query("").Select(
+					NewField("me").Select(Spread(a)),
+					NewField("other").Select(Spread(b)),
+				)
+			},
+			want: `fragment "UserFields" defined twice`,
+		},
+		{
+			name: "empty operation",
+			op: func() *Operation {
+				return Query("")
+			},
+			want: "empty selection set",
+		},
+		{
+			name: "empty fragment",
+			op: func() *Operation {
+				frag := NewFragment("UserFields", "User")
+				return Query("").Select(NewField("me").Select(Spread(frag)))
+			},
+			want: "empty selection set",
+		},
+		{
+			name: "empty inline fragment",
+			op: func() *Operation {
+				return Query("").Select(NewField("me").Select(On("User")))
+			},
+			want: "empty selection set",
+		},
+		{
+			name: "NaN",
+			op: func() *Operation {
+				return Query("").Select(NewField("near").Arg("lat", math.NaN()))
+			},
+			want: "invalid float value NaN",
+		},
+		{
+			name: "infinity",
+			op: func() *Operation {
+				return Query("").Select(NewField("near").Arg("lat", []float32{float32(math.Inf(-1))}))
+			},
+			want: "invalid float value -Inf",
+		},
+		{
+			name: "enum value",
+			op: func() *Operation {
+				return Query("").Select(NewField("users").Arg("role", Enum("SUPER ADMIN")))
+			},
+			want: `invalid enum value name "SUPER ADMIN"`,
+		},
+		{
+			name: "object field",
+			op: func() *Operation {
+				return Query("").Select(NewField("users").Arg("filter", map[string]interface{}{"created-at": 1}))
+			},
+			want: `invalid object field name "created-at"`,
+		},
+		{
+			name: "non-string map key",
+			op: func() *Operation {
+				return Query("").Select(NewField("users").Arg("filter", map[int]string{1: "a"}))
+			},
+			want: "unsupported map key type int",
+		},
+		{
+			name: "unsupported value",
+			op: func() *Operation {
+				return Query("").Select(NewField("users").Arg("filter", struct{ Name string }{}))
+			},
+			want: "unsupported value type struct { Name string }",
+		},
+		{
+			name: "named map key type",
+			op: func() *Operation {
+				return Query("").Select(NewField("users").Arg("filter", map[color]interface{}{"red": math.Inf(1)}))
+			},
+			want: "invalid float value +Inf",
+		},
+	}
+	for _, tc := range tests {
+		op := tc.op()
+		query, err := op.Render()
+		if err == nil {
+			t.Errorf("%v: Render() = %q, want an error", tc.name, query)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: Render() = %v, want %q", tc.name, err, tc.want)
+		}
+		if _, err := op.Build(); err == nil {
+			t.Errorf("%v: Build() succeeded", tc.name)
+		}
+	}
+}
+
+func TestRenderValues(t *testing.T) {
+	type color string
+
+	var nilPtr *int
+	n := 42
+	op := Query("")
+	v := op.Var("id", "ID!", "1")
+	op.Select(NewField("f").
+		Arg("var", v).
+		Arg("null", nil).
+		Arg("nilPtr", nilPtr).
+		Arg("ptr", &n).
+		Arg("bool", true).
+		Arg("int", int8(-3)).
+		Arg("uint", uint64(math.MaxUint64)).
+		Arg("float", 2.0).
+		Arg("exp", 1e21).
+		Arg("named", color("red")).
+		Arg("enum", Enum("RED")).
+		Arg("list", []interface{}{1, "a", nil}).
+		Arg("obj", map[color]interface{}{"b": []int{}, "a": map[string]bool{"c": false}}))
+
+	query, err := op.Render()
+	if err != nil {
+		t.Fatalf("Render() = %v", err)
+	}
+	want := `query($id: ID!) {
+	f(var: $id, null: null, nilPtr: null, ptr: 42, bool: true, int: -3, uint: 18446744073709551615, float: 2.0, exp: 1e+21, named: "red", enum: RED, list: [1, "a", null], obj: {a: {c: false}, b: []})
+}
+`
+	if query != want {
+		t.Errorf("Render() = \n%v\nwant:\n%v", query, want)
+	}
+}
+
+func TestQuoteString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "", want: `""`},
+		{in: `say "hi"`, want: `"say \"hi\""`},
+		{in: `C:\dir`, want: `"C:\\dir"`},
+		{in: "a\nb\r\tc\b\f", want: `"a\nb\r\tc\b\f"`},
+		{in: "\x00\x1f\x7f", want: `"\u0000\u001F\u007F"`},
+		{in: "héllo, 世界 👋", want: `"héllo, 世界 👋"`},
+		{in: "\u2028", want: "\"\u2028\""},
+		{in: "invalid \xff", want: "\"invalid \uFFFD\""},
+	}
+	for _, tc := range tests {
+		if got := quoteString(tc.in); got != tc.want {
+			t.Errorf("quoteString(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	var reqData struct {
+		Query     string
+		Variables map[string]interface{}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := json.NewDecoder(req.Body).Decode(&reqData); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{}}`))
+	}))
+	defer srv.Close()
+
+	op := Mutation("updateUser")
+	id := op.Var("id", "ID!", "1")
+	name := op.OptionalVar("name", "String")
+	op.Select(NewField("updateUser").Arg("id", id).Arg("name", name).Select(NewField("id")))
+
+	gqlOp, err := op.Build()
+	if err != nil {
+		t.Fatalf("Build() = %v", err)
+	}
+	c := gqlclient.New(srv.URL, nil)
+	if err := c.Execute(context.Background(), gqlOp, nil); err != nil {
+		t.Fatalf("Execute() = %v", err)
+	}
+
+	want := `mutation updateUser($id: ID!, $name: String) {
+	updateUser(id: $id, name: $name) {
+		id
+	}
+}
+`
+	if reqData.Query != want {
+		t.Errorf("Build() query = \n%v\nwant:\n%v", reqData.Query, want)
+	}
+	if len(reqData.Variables) != 1 || reqData.Variables["id"] != "1" {
+		t.Errorf("Build() vars = %v, want only id", reqData.Variables)
+	}
+}
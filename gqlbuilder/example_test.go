@@ -0,0 +1,49 @@
+package gqlbuilder_test
+
+import (
+	"fmt"
+	"log"
+
+	"git.sr.ht/~emersion/gqlclient/gqlbuilder"
+)
+
+func ExampleOperation() {
+	userFields := gqlbuilder.NewFragment("UserFields", "User").Select(
+		gqlbuilder.NewField("name"),
+		gqlbuilder.NewField("age"),
+	)
+
+	op := gqlbuilder.Query("fetchUsers")
+	name := op.Var("name", "String!", "emersion")
+	op.Select(
+		gqlbuilder.NewField("user").As("me").Arg("username", name).Select(
+			gqlbuilder.Spread(userFields),
+		),
+		gqlbuilder.NewField("users").Arg("filter", map[string]interface{}{
+			"role":  gqlbuilder.Enum("ADMIN"),
+			"query": "say \"hi\"",
+		}).Select(
+			gqlbuilder.Spread(userFields),
+		),
+	)
+
+	query, err := op.Render()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(query)
+	// Output:
+	// query fetchUsers($name: String!) {
+	// 	me: user(username: $name) {
+	// 		...UserFields
+	// 	}
+	// 	users(filter: {query: "say \"hi\"", role: ADMIN}) {
+	// 		...UserFields
+	// 	}
+	// }
+	//
+	// fragment UserFields on User {
+	// 	name
+	// 	age
+	// }
+}
@@ -0,0 +1,40 @@
+package gqltest_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"git.sr.ht/~emersion/gqlclient"
+	"git.sr.ht/~emersion/gqlclient/gqltest"
+)
+
+func ExampleServer() {
+	srv := gqltest.NewServer()
+	defer srv.Close()
+
+	h := srv.Handle(&gqltest.Response{
+		Data: map[string]interface{}{
+			"user": map[string]interface{}{"age": 42},
+		},
+	}, gqltest.OperationName("fetchUser"), gqltest.VarEquals("name", "emersion"))
+
+	op := gqlclient.NewOperation(`query fetchUser($name: String!) {
+		user(username: $name) {
+			age
+		}
+	}`)
+	op.Var("name", "emersion")
+
+	var data struct {
+		User struct {
+			Age int
+		}
+	}
+	if err := srv.Client().Execute(context.Background(), op, &data); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(data.User.Age, len(h.Calls()))
+	// Output: 42 1
+}
@@ -0,0 +1,425 @@
+// Package gqltest provides a fake GraphQL server for tests.
+//
+// Tests register canned responses on a Server, matched by operation name,
+// query or variables, then point a gqlclient.Client at it. Requests received
+// by the server are recorded so that tests can assert on variables, header
+// fields and uploads.
+//
+// Mock generates fake data for any query from a GraphQL schema.
+//
+// Recorder can be used to capture real GraphQL traffic once and replay it
+// offline.
+package gqltest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+
+	"git.sr.ht/~emersion/gqlclient"
+)
+
+// Request is a GraphQL request received by a Server.
+type Request struct {
+	// Name of the operation, if any
+	OperationName string
+	Query         string
+	Variables     map[string]interface{}
+	Header        http.Header
+	// Files uploaded with the request, indexed by variable path (e.g. "file"
+	// or "files.0")
+	Uploads map[string]*Upload
+}
+
+// Upload is a file uploaded with a request.
+type Upload struct {
+	Filename string
+	MIMEType string
+	Body     []byte
+}
+
+// Response is a canned response sent by a Server.
+type Response struct {
+	// Data sent in the response, marshaled to JSON
+	Data interface{}
+	// GraphQL errors sent in the response. Together with Data, this can be
+	// used to simulate partial results.
+	Errors []gqlclient.Error
+
+	// HTTP status code, defaults to 200
+	StatusCode int
+	// Additional HTTP header fields
+	Header http.Header
+	// Raw HTTP response body. If non-empty, Data and Errors are ignored and
+	// the Content-Type defaults to text/plain.
+	Body string
+
+	// Delay before the response is sent
+	Delay time.Duration
+}
+
+// Matcher checks whether a request should be handled by a Handler.
+type Matcher func(req *Request) bool
+
+// OperationName matches requests by operation name.
+func OperationName(name string) Matcher {
+	return func(req *Request) bool {
+		return req.OperationName == name
+	}
+}
+
+// QueryContains matches requests whose query contains the specified string.
+func QueryContains(s string) Matcher {
+	return func(req *Request) bool {
+		return strings.Contains(req.Query, s)
+	}
+}
+
+// Var matches requests whose variable satisfies the specified predicate. The
+// predicate receives the variable decoded from JSON, or nil if the variable is
+// missing.
+func Var(name string, pred func(v interface{}) bool) Matcher {
+	return func(req *Request) bool {
+		return pred(req.Variables[name])
+	}
+}
+
+// VarEquals matches requests whose variable is equal to v once both are
+// encoded to JSON.
+func VarEquals(name string, v interface{}) Matcher {
+	return Var(name, func(got interface{}) bool {
+		return jsonEqual(got, v)
+	})
+}
+
+// Handler responds to requests matching a list of matchers.
+type Handler struct {
+	matchers []Matcher
+	fn       func(req *Request) *Response
+
+	mutex sync.Mutex
+	calls []*Request
+}
+
+func (h *Handler) match(req *Request) bool {
+	for _, m := range h.matchers {
+		if !m(req) {
+			return false
+		}
+	}
+	return true
+}
+
+// Calls returns the requests handled so far.
+func (h *Handler) Calls() []*Request {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return append([]*Request(nil), h.calls...)
+}
+
+// Server is a fake GraphQL server.
+type Server struct {
+	// URL of the GraphQL endpoint
+	URL string
+
+	srv *httptest.Server
+
+	mutex    sync.Mutex
+	handlers []*Handler
+	requests []*Request
+}
+
+// NewServer starts a new fake GraphQL server. The caller should call Close
+// when finished, to shut it down.
+func NewServer() *Server {
+	s := new(Server)
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns a GraphQL client configured to send requests to the server.
+func (s *Server) Client() *gqlclient.Client {
+	return gqlclient.New(s.URL, s.srv.Client())
+}
+
+// Handle registers a canned response for requests satisfying all matchers.
+//
+// Handlers are tried in registration order. Requests not matching any
+// handler are responded to with a GraphQL error.
+func (s *Server) Handle(resp *Response, matchers ...Matcher) *Handler {
+	return s.HandleFunc(func(req *Request) *Response {
+		return resp
+	}, matchers...)
+}
+
+// HandleFunc registers a function building responses for requests satisfying
+// all matchers.
+func (s *Server) HandleFunc(fn func(req *Request) *Response, matchers ...Matcher) *Handler {
+	h := &Handler{matchers: matchers, fn: fn}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.handlers = append(s.handlers, h)
+	return h
+}
+
+// Requests returns all requests received so far, including the ones which
+// didn't match any handler.
+func (s *Server) Requests() []*Request {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]*Request(nil), s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "gqltest: unsupported method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req, err := readRequest(r.Header, r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("gqltest: invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	s.requests = append(s.requests, req)
+	var handler *Handler
+	for _, h := range s.handlers {
+		if h.match(req) {
+			handler = h
+			break
+		}
+	}
+	s.mutex.Unlock()
+
+	var resp *Response
+	if handler != nil {
+		handler.mutex.Lock()
+		handler.calls = append(handler.calls, req)
+		handler.mutex.Unlock()
+
+		resp = handler.fn(req)
+	}
+	if resp == nil {
+		resp = &Response{
+			Errors: []gqlclient.Error{{Message: "gqltest: no response registered for request"}},
+		}
+	}
+
+	if resp.Delay > 0 {
+		t := time.NewTimer(resp.Delay)
+		select {
+		case <-t.C:
+		case <-r.Context().Done():
+			t.Stop()
+			return
+		}
+	}
+
+	writeResponse(w, resp)
+}
+
+// encodings lists the content codings accepted for request bodies, by the
+// fake server and by the recorder.
+var encodings = []*gqlclient.Encoding{gqlclient.Gzip, gqlclient.Zstd, gqlclient.Brotli}
+
+func readRequest(header http.Header, body io.Reader) (*Request, error) {
+	req := &Request{Header: header}
+
+	rc, err := gqlclient.Decompress(encodings, header.Get("Content-Encoding"), body)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	body = rc
+
+	var operations io.Reader = body
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Type: %v", err)
+	}
+	var form *multipart.Form
+	if mediaType == "multipart/form-data" {
+		mr := multipart.NewReader(body, params["boundary"])
+		form, err = mr.ReadForm(32 << 20)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read multipart form: %v", err)
+		}
+		defer form.RemoveAll()
+
+		if len(form.Value["operations"]) != 1 {
+			return nil, fmt.Errorf("missing operations part")
+		}
+		operations = strings.NewReader(form.Value["operations"][0])
+	}
+
+	var payload struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(operations).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %v", err)
+	}
+	req.Query = payload.Query
+	req.Variables = payload.Variables
+	req.OperationName = payload.OperationName
+	if req.OperationName == "" {
+		req.OperationName = operationName(req.Query)
+	}
+
+	if form != nil {
+		if req.Uploads, err = readUploads(form); err != nil {
+			return nil, err
+		}
+	}
+
+	return req, nil
+}
+
+func readUploads(form *multipart.Form) (map[string]*Upload, error) {
+	if len(form.Value["map"]) != 1 {
+		return nil, fmt.Errorf("missing map part")
+	}
+	var m map[string][]string
+	if err := json.Unmarshal([]byte(form.Value["map"][0]), &m); err != nil {
+		return nil, fmt.Errorf("failed to decode map part: %v", err)
+	}
+
+	uploads := make(map[string]*Upload)
+	for k, paths := range m {
+		if len(form.File[k]) != 1 {
+			return nil, fmt.Errorf("missing file part %q", k)
+		}
+		fh := form.File[k][0]
+
+		f, err := fh.Open()
+		if err != nil {
+			return nil, err
+		}
+		b, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file part %q: %v", k, err)
+		}
+
+		upload := &Upload{
+			Filename: fh.Filename,
+			MIMEType: fh.Header.Get("Content-Type"),
+			Body:     b,
+		}
+		for _, path := range paths {
+			uploads[strings.TrimPrefix(path, "variables.")] = upload
+		}
+	}
+	return uploads, nil
+}
+
+// operationName extracts the name of the single operation defined in a query
+// document.
+func operationName(query string) string {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil || len(doc.Operations) != 1 {
+		return ""
+	}
+	return doc.Operations[0].Name
+}
+
+func writeResponse(w http.ResponseWriter, resp *Response) {
+	for k, values := range resp.Header {
+		for _, v := range values {
+			w.Header().Add(k, v)
+		}
+	}
+
+	statusCode := resp.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	if resp.Body != "" {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		}
+		w.WriteHeader(statusCode)
+		io.WriteString(w, resp.Body)
+		return
+	}
+
+	payload := struct {
+		Data   interface{}   `json:"data"`
+		Errors []interface{} `json:"errors,omitempty"`
+	}{Data: resp.Data}
+	for _, err := range resp.Errors {
+		payload.Errors = append(payload.Errors, marshalError(&err))
+	}
+
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(&payload)
+}
+
+func marshalError(err *gqlclient.Error) interface{} {
+	type location struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	}
+	v := struct {
+		Message    string          `json:"message"`
+		Locations  []location      `json:"locations,omitempty"`
+		Path       []interface{}   `json:"path,omitempty"`
+		Extensions json.RawMessage `json:"extensions,omitempty"`
+	}{
+		Message:    err.Message,
+		Path:       err.Path,
+		Extensions: err.Extensions,
+	}
+	for _, loc := range err.Locations {
+		v.Locations = append(v.Locations, location{Line: loc.Line, Column: loc.Column})
+	}
+	return v
+}
+
+func jsonEqual(a, b interface{}) bool {
+	normalize := func(v interface{}) (interface{}, bool) {
+		buf, err := json.Marshal(v)
+		if err != nil {
+			return nil, false
+		}
+		var out interface{}
+		if err := json.Unmarshal(buf, &out); err != nil {
+			return nil, false
+		}
+		return out, true
+	}
+	na, ok := normalize(a)
+	if !ok {
+		return false
+	}
+	nb, ok := normalize(b)
+	if !ok {
+		return false
+	}
+	return reflect.DeepEqual(na, nb)
+}
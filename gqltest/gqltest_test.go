@@ -0,0 +1,264 @@
+package gqltest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~emersion/gqlclient"
+	"git.sr.ht/~emersion/gqlclient/gqltest"
+)
+
+func TestServerCompressedRequest(t *testing.T) {
+	srv := gqltest.NewServer()
+	defer srv.Close()
+
+	h := srv.Handle(&gqltest.Response{
+		Data: map[string]interface{}{"hello": "world"},
+	}, gqltest.VarEquals("name", "emersion"))
+
+	for _, enc := range []*gqlclient.Encoding{gqlclient.Gzip, gqlclient.Zstd, gqlclient.Brotli} {
+		c := srv.Client()
+		c.RequestEncoding = enc
+
+		op := gqlclient.NewOperation(`query ($name: String!) { hello(name: $name) }`)
+		op.Var("name", "emersion")
+
+		var data struct {
+			Hello string
+		}
+		if err := c.Execute(context.Background(), op, &data); err != nil {
+			t.Fatalf("Execute() with %v = %v", enc.Name, err)
+		}
+		if data.Hello != "world" {
+			t.Errorf("hello with %v = %q, want %q", enc.Name, data.Hello, "world")
+		}
+	}
+
+	if n := len(h.Calls()); n != 3 {
+		t.Errorf("got %v calls, want 3", n)
+	}
+}
+
+func TestServerMatchers(t *testing.T) {
+	srv := gqltest.NewServer()
+	defer srv.Close()
+
+	byName := srv.Handle(&gqltest.Response{Data: map[string]interface{}{"v": "name"}}, gqltest.OperationName("named"))
+	byVars := srv.Handle(&gqltest.Response{Data: map[string]interface{}{"v": "vars"}},
+		gqltest.QueryContains("user"),
+		gqltest.VarEquals("id", 42),
+		gqltest.Var("tags", func(v interface{}) bool {
+			tags, _ := v.([]interface{})
+			return len(tags) == 2
+		}))
+	byQuery := srv.Handle(&gqltest.Response{Data: map[string]interface{}{"v": "query"}}, gqltest.QueryContains("user"))
+
+	tests := []struct {
+		query string
+		vars  map[string]interface{}
+		want  string
+	}{
+		{query: `query named { v }`, want: "name"},
+		{query: `query ($id: Int, $tags: [String]) { user { v } }`, vars: map[string]interface{}{"id": 42, "tags": []string{"a", "b"}}, want: "vars"},
+		{query: `query ($id: Int, $tags: [String]) { user { v } }`, vars: map[string]interface{}{"id": 42, "tags": []string{"a"}}, want: "query"},
+		{query: `query ($id: Int) { user { v } }`, vars: map[string]interface{}{"id": 1}, want: "query"},
+	}
+	for _, tc := range tests {
+		op := gqlclient.NewOperation(tc.query)
+		for k, v := range tc.vars {
+			op.Var(k, v)
+		}
+		var data struct {
+			V string
+		}
+		if err := srv.Client().Execute(context.Background(), op, &data); err != nil {
+			t.Fatalf("Execute(%q) = %v", tc.query, err)
+		}
+		if data.V != tc.want {
+			t.Errorf("Execute(%q) with %v matched %q, want %q", tc.query, tc.vars, data.V, tc.want)
+		}
+	}
+
+	for h, want := range map[*gqltest.Handler]int{byName: 1, byVars: 1, byQuery: 2} {
+		if n := len(h.Calls()); n != want {
+			t.Errorf("handler got %v calls, want %v", n, want)
+		}
+	}
+
+	// Unmatched requests get a GraphQL error
+	err := srv.Client().Execute(context.Background(), gqlclient.NewOperation(`query other { v }`), nil)
+	var gqlErr *gqlclient.Error
+	if !errors.As(err, &gqlErr) {
+		t.Errorf("Execute() for an unmatched request = %v, want a GraphQL error", err)
+	}
+	if n := len(srv.Requests()); n != 5 {
+		t.Errorf("got %v requests, want 5", n)
+	}
+	if name := srv.Requests()[4].OperationName; name != "other" {
+		t.Errorf("operation name = %q, want %q", name, "other")
+	}
+}
+
+// headerTransport adds header fields to requests sent with
+// http.DefaultTransport.
+type headerTransport http.Header
+
+func (tr headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return tr.wrap(http.DefaultTransport).RoundTrip(req)
+}
+
+// wrap returns a transport adding header fields to requests sent with next.
+func (tr headerTransport) wrap(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		for k, v := range tr {
+			req.Header[k] = v
+		}
+		return next.RoundTrip(req)
+	})
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestServerHeader(t *testing.T) {
+	srv := gqltest.NewServer()
+	defer srv.Close()
+
+	h := srv.Handle(&gqltest.Response{Data: map[string]interface{}{}})
+
+	hc := &http.Client{Transport: headerTransport{"Authorization": {"Bearer secret"}}}
+	c := gqlclient.New(srv.URL, hc)
+	if err := c.Execute(context.Background(), gqlclient.NewOperation(`query { v }`), nil); err != nil {
+		t.Fatalf("Execute() = %v", err)
+	}
+
+	calls := h.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("got %v calls, want 1", len(calls))
+	}
+	if got := calls[0].Header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q, want %q", got, "Bearer secret")
+	}
+	if got := calls[0].Header.Get("Content-Type"); !strings.HasPrefix(got, "application/json") {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+}
+
+func TestServerPartialData(t *testing.T) {
+	srv := gqltest.NewServer()
+	defer srv.Close()
+
+	srv.Handle(&gqltest.Response{
+		Data: map[string]interface{}{"a": 1, "b": nil},
+		Errors: []gqlclient.Error{{
+			Message:   "b failed",
+			Locations: []gqlclient.ErrorLocation{{Line: 1, Column: 13}},
+			Path:      []interface{}{"b"},
+		}},
+	})
+
+	var data struct {
+		A int
+		B *int
+	}
+	err := srv.Client().Execute(context.Background(), gqlclient.NewOperation(`query { a b }`), &data)
+	var gqlErr *gqlclient.Error
+	if !errors.As(err, &gqlErr) {
+		t.Fatalf("Execute() = %v, want a GraphQL error", err)
+	}
+	if gqlErr.Message != "b failed" || len(gqlErr.Locations) != 1 || gqlErr.Locations[0].Column != 13 || !reflect.DeepEqual(gqlErr.Path, []interface{}{"b"}) {
+		t.Errorf("got error %+v", gqlErr)
+	}
+	if data.A != 1 || data.B != nil {
+		t.Errorf("got data %+v, want partial data", data)
+	}
+}
+
+func TestServerHTTPError(t *testing.T) {
+	srv := gqltest.NewServer()
+	defer srv.Close()
+
+	srv.Handle(&gqltest.Response{StatusCode: http.StatusServiceUnavailable, Body: "maintenance"}, gqltest.OperationName("raw"))
+	srv.Handle(&gqltest.Response{
+		StatusCode: http.StatusUnauthorized,
+		Header:     http.Header{"Www-Authenticate": {"Bearer"}},
+		Errors:     []gqlclient.Error{{Message: "unauthorized"}},
+	})
+
+	var httpErr *gqlclient.HTTPError
+	err := srv.Client().Execute(context.Background(), gqlclient.NewOperation(`query raw { v }`), nil)
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Execute() with a raw body = %v, want an HTTP 503 error", err)
+	}
+
+	err = srv.Client().Execute(context.Background(), gqlclient.NewOperation(`query { v }`), nil)
+	var gqlErr *gqlclient.Error
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized || !errors.As(err, &gqlErr) {
+		t.Errorf("Execute() with GraphQL errors = %v, want an HTTP 401 error wrapping a GraphQL error", err)
+	}
+}
+
+func TestServerDelay(t *testing.T) {
+	srv := gqltest.NewServer()
+	defer srv.Close()
+
+	const delay = 50 * time.Millisecond
+	srv.Handle(&gqltest.Response{Data: map[string]interface{}{}, Delay: delay})
+
+	start := time.Now()
+	if err := srv.Client().Execute(context.Background(), gqlclient.NewOperation(`query { v }`), nil); err != nil {
+		t.Fatalf("Execute() = %v", err)
+	}
+	if d := time.Since(start); d < delay {
+		t.Errorf("response took %v, want at least %v", d, delay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), delay/5)
+	defer cancel()
+	err := srv.Client().Execute(ctx, gqlclient.NewOperation(`query { v }`), nil)
+	if err == nil || ctx.Err() == nil {
+		t.Errorf("Execute() with a shorter timeout = %v, want an error", err)
+	}
+}
+
+func TestServerUploads(t *testing.T) {
+	srv := gqltest.NewServer()
+	defer srv.Close()
+
+	h := srv.Handle(&gqltest.Response{Data: map[string]interface{}{"ok": true}})
+
+	op := gqlclient.NewOperation(`mutation ($file: Upload!, $files: [Upload!]!) { upload(file: $file, files: $files) }`)
+	op.Var("file", gqlclient.Upload{Filename: "a.txt", MIMEType: "text/plain", Body: strings.NewReader("hello")})
+	op.Var("files", []gqlclient.Upload{
+		{Filename: "b.png", MIMEType: "image/png", Body: strings.NewReader("\x89PNG")},
+		{Filename: "c.txt", MIMEType: "text/plain", Body: strings.NewReader("world")},
+	})
+	if err := srv.Client().Execute(context.Background(), op, nil); err != nil {
+		t.Fatalf("Execute() = %v", err)
+	}
+
+	calls := h.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("got %v calls, want 1", len(calls))
+	}
+	want := map[string]*gqltest.Upload{
+		"file":    {Filename: "a.txt", MIMEType: "text/plain", Body: []byte("hello")},
+		"files.0": {Filename: "b.png", MIMEType: "image/png", Body: []byte("\x89PNG")},
+		"files.1": {Filename: "c.txt", MIMEType: "text/plain", Body: []byte("world")},
+	}
+	if !reflect.DeepEqual(calls[0].Uploads, want) {
+		t.Errorf("got uploads %+v, want %+v", calls[0].Uploads, want)
+	}
+	if !strings.Contains(calls[0].Query, "mutation") {
+		t.Errorf("got query %q", calls[0].Query)
+	}
+}
@@ -0,0 +1,304 @@
+package gqltest
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"git.sr.ht/~emersion/gqlclient"
+)
+
+// ScalarGenerator generates a fake value for a custom scalar.
+type ScalarGenerator func(r *rand.Rand) interface{}
+
+// MockOptions contains options for NewMock.
+type MockOptions struct {
+	// Seed of the pseudo-random generator. Mocks created with the same seed
+	// generate the same data for the same sequence of requests.
+	Seed int64
+	// Generators for custom scalars, indexed by GraphQL scalar name. The
+	// scalars with a Go representation provided by gqlclient (Time, Date,
+	// Duration, UUID, Decimal, BigInt, JSON, Map and Any) have default
+	// generators, which can be overridden. Other custom scalars without a
+	// generator are mocked as strings.
+	Scalars map[string]ScalarGenerator
+	// Maximum number of items in generated lists, defaults to 3.
+	MaxListLength int
+	// Probability in [0, 1] of generating null for a nullable field. Defaults
+	// to 0.
+	NullRate float64
+}
+
+// Mock generates type-correct fake data for any query, based on a GraphQL
+// schema.
+//
+// The schema can be loaded with gqlparser.LoadSchema, in the same way as
+// gqlclientgen.
+type Mock struct {
+	schema  *ast.Schema
+	options MockOptions
+
+	mutex sync.Mutex
+	rand  *rand.Rand
+}
+
+// NewMock creates a new mock for the specified schema.
+//
+// If options is nil, default options are used.
+func NewMock(schema *ast.Schema, options *MockOptions) *Mock {
+	m := &Mock{schema: schema}
+	if options != nil {
+		m.options = *options
+	}
+	if m.options.MaxListLength <= 0 {
+		m.options.MaxListLength = 3
+	}
+	m.rand = rand.New(rand.NewSource(m.options.Seed))
+	return m
+}
+
+// Respond generates a response for a request. It can be passed to
+// Server.HandleFunc.
+//
+// Requests which fail validation against the schema are responded to with
+// GraphQL errors.
+func (m *Mock) Respond(req *Request) *Response {
+	doc, gqlErrs := gqlparser.LoadQuery(m.schema, req.Query)
+	if len(gqlErrs) > 0 {
+		resp := new(Response)
+		for _, gqlErr := range gqlErrs {
+			err := gqlclient.Error{Message: gqlErr.Message}
+			for _, loc := range gqlErr.Locations {
+				err.Locations = append(err.Locations, gqlclient.ErrorLocation{
+					Line:   loc.Line,
+					Column: loc.Column,
+				})
+			}
+			resp.Errors = append(resp.Errors, err)
+		}
+		return resp
+	}
+
+	var op *ast.OperationDefinition
+	if req.OperationName != "" {
+		op = doc.Operations.ForName(req.OperationName)
+	} else if len(doc.Operations) == 1 {
+		op = doc.Operations[0]
+	}
+	if op == nil {
+		return &Response{
+			Errors: []gqlclient.Error{{Message: "gqltest: unknown operation"}},
+		}
+	}
+
+	var root *ast.Definition
+	switch op.Operation {
+	case ast.Query:
+		root = m.schema.Query
+	case ast.Mutation:
+		root = m.schema.Mutation
+	case ast.Subscription:
+		root = m.schema.Subscription
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	g := mockGenerator{Mock: m, vars: req.Variables}
+	return &Response{Data: g.object(root, op.SelectionSet)}
+}
+
+type mockGenerator struct {
+	*Mock
+	vars map[string]interface{}
+}
+
+func (g *mockGenerator) object(def *ast.Definition, selSet ast.SelectionSet) map[string]interface{} {
+	var keys []string
+	fields := make(map[string][]*ast.Field)
+	g.collectFields(&keys, fields, def, selSet)
+
+	out := make(map[string]interface{})
+	for _, key := range keys {
+		l := fields[key]
+		if l[0].Name == "__typename" {
+			out[key] = def.Name
+			continue
+		}
+
+		// Merge sub-selections of fields selected multiple times
+		var subSelSet ast.SelectionSet
+		for _, field := range l {
+			subSelSet = append(subSelSet, field.SelectionSet...)
+		}
+		out[key] = g.value(l[0].Definition.Type, subSelSet)
+	}
+	return out
+}
+
+// collectFields groups the fields selected on an object type by response key.
+func (g *mockGenerator) collectFields(keys *[]string, fields map[string][]*ast.Field, def *ast.Definition, selSet ast.SelectionSet) {
+	for _, sel := range selSet {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if !g.included(sel.Directives) {
+				continue
+			}
+			key := sel.Alias
+			if key == "" {
+				key = sel.Name
+			}
+			if _, ok := fields[key]; !ok {
+				*keys = append(*keys, key)
+			}
+			fields[key] = append(fields[key], sel)
+		case *ast.FragmentSpread:
+			if g.included(sel.Directives) && g.applies(def, sel.Definition.TypeCondition) {
+				g.collectFields(keys, fields, def, sel.Definition.SelectionSet)
+			}
+		case *ast.InlineFragment:
+			if g.included(sel.Directives) && g.applies(def, sel.TypeCondition) {
+				g.collectFields(keys, fields, def, sel.SelectionSet)
+			}
+		default:
+			panic(fmt.Sprintf("unsupported selection type: %T", sel))
+		}
+	}
+}
+
+// applies checks whether a fragment with the specified type condition applies
+// to an object type.
+func (g *mockGenerator) applies(def *ast.Definition, typeCond string) bool {
+	if typeCond == "" || typeCond == def.Name {
+		return true
+	}
+	cond := g.schema.Types[typeCond]
+	if cond == nil || !cond.IsAbstractType() {
+		return false
+	}
+	for _, typ := range g.schema.GetPossibleTypes(cond) {
+		if typ.Name == def.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// included evaluates @skip and @include directives.
+func (g *mockGenerator) included(directives ast.DirectiveList) bool {
+	if d := directives.ForName("skip"); d != nil {
+		if v, _ := d.ArgumentMap(g.vars)["if"].(bool); v {
+			return false
+		}
+	}
+	if d := directives.ForName("include"); d != nil {
+		if v, _ := d.ArgumentMap(g.vars)["if"].(bool); !v {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *mockGenerator) value(t *ast.Type, selSet ast.SelectionSet) interface{} {
+	if !t.NonNull && g.options.NullRate > 0 && g.rand.Float64() < g.options.NullRate {
+		return nil
+	}
+
+	if t.Elem != nil {
+		n := 1 + g.rand.Intn(g.options.MaxListLength)
+		l := make([]interface{}, n)
+		for i := range l {
+			l[i] = g.value(t.Elem, selSet)
+		}
+		return l
+	}
+
+	def := g.schema.Types[t.NamedType]
+	switch def.Kind {
+	case ast.Scalar:
+		return g.scalar(def)
+	case ast.Enum:
+		return def.EnumValues[g.rand.Intn(len(def.EnumValues))].Name
+	case ast.Object:
+		return g.object(def, selSet)
+	case ast.Interface, ast.Union:
+		possibleTypes := g.schema.GetPossibleTypes(def)
+		if len(possibleTypes) == 0 {
+			return nil
+		}
+		typ := possibleTypes[g.rand.Intn(len(possibleTypes))]
+		return g.object(typ, selSet)
+	default:
+		panic(fmt.Sprintf("unsupported definition kind: %s", def.Kind))
+	}
+}
+
+// defaultScalars contains generators for the scalars with a Go representation
+// provided by gqlclient. Values are valid for these Go types.
+var defaultScalars = map[string]ScalarGenerator{
+	"Time": func(r *rand.Rand) interface{} {
+		return gqlclient.Time{Time: randomTime(r)}
+	},
+	"Date": func(r *rand.Rand) interface{} {
+		return gqlclient.Date{Time: randomTime(r).Truncate(24 * time.Hour)}
+	},
+	"Duration": func(r *rand.Rand) interface{} {
+		return gqlclient.Duration{Duration: time.Duration(1+r.Intn(24*60*60)) * time.Second}
+	},
+	"UUID": func(r *rand.Rand) interface{} {
+		var u gqlclient.UUID
+		r.Read(u[:])
+		u[6] = u[6]&0x0f | 0x40 // version 4
+		u[8] = u[8]&0x3f | 0x80 // RFC 4122 variant
+		return u
+	},
+	"Decimal": func(r *rand.Rand) interface{} {
+		return gqlclient.Decimal{Rat: big.NewRat(r.Int63n(1000000), 100)}
+	},
+	"BigInt": func(r *rand.Rand) interface{} {
+		return gqlclient.BigInt{Int: big.NewInt(r.Int63())}
+	},
+	"JSON": randomMap,
+	"Map":  randomMap,
+	"Any": func(r *rand.Rand) interface{} {
+		return r.Int31n(1000)
+	},
+}
+
+// randomTime returns a time between 2000 and 2030, with a precision of one
+// second.
+func randomTime(r *rand.Rand) time.Time {
+	start := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	return start.Add(time.Duration(r.Int63n(30*365*24*60*60)) * time.Second)
+}
+
+func randomMap(r *rand.Rand) interface{} {
+	return map[string]interface{}{"value": r.Int31n(1000)}
+}
+
+func (g *mockGenerator) scalar(def *ast.Definition) interface{} {
+	if gen, ok := g.options.Scalars[def.Name]; ok {
+		return gen(g.rand)
+	}
+	if gen, ok := defaultScalars[def.Name]; ok {
+		return gen(g.rand)
+	}
+
+	switch def.Name {
+	case "Int":
+		return g.rand.Int31n(1000)
+	case "Float":
+		return g.rand.Float64() * 1000
+	case "Boolean":
+		return g.rand.Intn(2) == 1
+	case "ID":
+		return fmt.Sprintf("%x", g.rand.Uint64())
+	default:
+		return fmt.Sprintf("%v-%v", def.Name, g.rand.Intn(1000))
+	}
+}
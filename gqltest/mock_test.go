@@ -0,0 +1,315 @@
+package gqltest_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"git.sr.ht/~emersion/gqlclient"
+	"git.sr.ht/~emersion/gqlclient/gqltest"
+)
+
+const mockSchema = `
+scalar Time
+scalar Date
+scalar Duration
+scalar UUID
+scalar Decimal
+scalar BigInt
+scalar JSON
+scalar Map
+scalar Any
+scalar Color
+
+enum Role { ADMIN USER }
+
+interface Node { id: ID! }
+
+type User implements Node {
+	id: ID!
+	name: String!
+	nickname: String
+	age: Int!
+	score: Float!
+	admin: Boolean!
+	role: Role!
+	friends: [User!]!
+	tags: [String]
+	created: Time!
+	birthday: Date!
+	session: Duration!
+	uuid: UUID!
+	balance: Decimal!
+	followers: BigInt!
+	settings: JSON!
+	attrs: Map!
+	extra: Any!
+	color: Color!
+}
+
+type Bot implements Node {
+	id: ID!
+	owner: User!
+}
+
+union Thing = User | Bot
+
+type Query {
+	me: User!
+	user: User
+	node: Node!
+	things: [Thing!]!
+}
+`
+
+func newMock(t *testing.T, options *gqltest.MockOptions) *gqltest.Mock {
+	schema, gqlErr := gqlparser.LoadSchema(&ast.Source{Input: mockSchema})
+	if gqlErr != nil {
+		t.Fatal(gqlErr)
+	}
+	return gqltest.NewMock(schema, options)
+}
+
+func mockData(t *testing.T, m *gqltest.Mock, query string, vars map[string]interface{}) map[string]interface{} {
+	resp := m.Respond(&gqltest.Request{Query: query, Variables: vars})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("Respond(%q) = %v", query, resp.Errors)
+	}
+	b, err := json.Marshal(resp.Data)
+	if err != nil {
+		t.Fatalf("failed to encode data: %v", err)
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		t.Fatalf("failed to decode data: %v", err)
+	}
+	return data
+}
+
+func TestMockSeed(t *testing.T) {
+	const query = `query { me { id name age friends { id } created uuid } }`
+
+	a := mockData(t, newMock(t, &gqltest.MockOptions{Seed: 42}), query, nil)
+	b := mockData(t, newMock(t, &gqltest.MockOptions{Seed: 42}), query, nil)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("mocks with the same seed generated different data:\n%v\n%v", a, b)
+	}
+
+	c := mockData(t, newMock(t, &gqltest.MockOptions{Seed: 43}), query, nil)
+	if reflect.DeepEqual(a, c) {
+		t.Errorf("mocks with different seeds generated the same data: %v", a)
+	}
+}
+
+func TestMockScalars(t *testing.T) {
+	m := newMock(t, &gqltest.MockOptions{
+		Scalars: map[string]gqltest.ScalarGenerator{
+			"Color": func(r *rand.Rand) interface{} {
+				return "#ff0000"
+			},
+		},
+	})
+
+	srv := gqltest.NewServer()
+	defer srv.Close()
+	srv.HandleFunc(m.Respond)
+
+	op := gqlclient.NewOperation(`query {
+		me {
+			id name age score admin role
+			created birthday session uuid balance followers settings attrs extra color
+		}
+	}`)
+	var data struct {
+		Me struct {
+			ID        string
+			Name      string
+			Age       int32
+			Score     float64
+			Admin     bool
+			Role      string
+			Created   gqlclient.Time
+			Birthday  gqlclient.Date
+			Session   gqlclient.Duration
+			UUID      gqlclient.UUID
+			Balance   gqlclient.Decimal
+			Followers gqlclient.BigInt
+			Settings  gqlclient.JSON
+			Attrs     map[string]interface{}
+			Extra     interface{}
+			Color     string
+		}
+	}
+	for i := 0; i < 10; i++ {
+		if err := srv.Client().Execute(context.Background(), op, &data); err != nil {
+			t.Fatalf("Execute() = %v", err)
+		}
+
+		me := &data.Me
+		if me.Role != "ADMIN" && me.Role != "USER" {
+			t.Errorf("role = %q, want an enum value", me.Role)
+		}
+		if me.Created.IsZero() || me.Birthday.IsZero() || me.Session.Duration <= 0 || me.UUID.IsZero() || me.Balance.Rat == nil || me.Followers.Int == nil || me.Settings == nil || me.Attrs == nil || me.Extra == nil {
+			t.Errorf("got zero custom scalars: %+v", me)
+		}
+		if me.Color != "#ff0000" {
+			t.Errorf("color = %q, want the custom generator value", me.Color)
+		}
+	}
+}
+
+func TestMockLists(t *testing.T) {
+	m := newMock(t, &gqltest.MockOptions{MaxListLength: 2, NullRate: 1})
+
+	for i := 0; i < 10; i++ {
+		data := mockData(t, m, `query { me { friends { id tags } tags } }`, nil)
+		me := data["me"].(map[string]interface{})
+		friends, ok := me["friends"].([]interface{})
+		if !ok || len(friends) < 1 || len(friends) > 2 {
+			t.Fatalf("friends = %v, want a non-null list of 1 to 2 elements", me["friends"])
+		}
+		for _, friend := range friends {
+			friend, ok := friend.(map[string]interface{})
+			if !ok {
+				t.Fatalf("friend = %v, want a non-null object", friend)
+			}
+			if friend["tags"] != nil {
+				t.Errorf("tags = %v, want null", friend["tags"])
+			}
+		}
+		if me["tags"] != nil {
+			t.Errorf("tags = %v, want null", me["tags"])
+		}
+	}
+}
+
+func TestMockNullRate(t *testing.T) {
+	const query = `query { user { id } me { nickname tags } }`
+
+	data := mockData(t, newMock(t, &gqltest.MockOptions{NullRate: 1}), query, nil)
+	if data["user"] != nil {
+		t.Errorf("user = %v, want null", data["user"])
+	}
+	me := data["me"].(map[string]interface{})
+	if me["nickname"] != nil || me["tags"] != nil {
+		t.Errorf("me = %v, want nullable fields set to null", me)
+	}
+
+	m := newMock(t, nil)
+	for i := 0; i < 10; i++ {
+		data := mockData(t, m, query, nil)
+		me := data["me"].(map[string]interface{})
+		if data["user"] == nil || me["nickname"] == nil || me["tags"] == nil {
+			t.Errorf("got null values with the default NullRate: %v", data)
+		}
+		for _, tag := range me["tags"].([]interface{}) {
+			if tag == nil {
+				t.Errorf("got null list element with the default NullRate: %v", data)
+			}
+		}
+	}
+}
+
+func TestMockTypename(t *testing.T) {
+	m := newMock(t, nil)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 10; i++ {
+		data := mockData(t, m, `query {
+			node { __typename id ... on Bot { owner { id } } }
+			things {
+				__typename
+				... on User { name }
+				... on Bot { owner { __typename } }
+			}
+		}`, nil)
+
+		node := data["node"].(map[string]interface{})
+		switch typename := node["__typename"]; typename {
+		case "User":
+			if _, ok := node["owner"]; ok {
+				t.Errorf("user has a bot field: %v", node)
+			}
+		case "Bot":
+			if _, ok := node["owner"]; !ok {
+				t.Errorf("bot is missing a field: %v", node)
+			}
+		default:
+			t.Errorf("node __typename = %v", typename)
+		}
+		if _, ok := node["id"]; !ok {
+			t.Errorf("node is missing an interface field: %v", node)
+		}
+
+		for _, thing := range data["things"].([]interface{}) {
+			thing := thing.(map[string]interface{})
+			typename, _ := thing["__typename"].(string)
+			seen[typename] = true
+			var wantKeys []string
+			switch typename {
+			case "User":
+				wantKeys = []string{"__typename", "name"}
+			case "Bot":
+				wantKeys = []string{"__typename", "owner"}
+				if owner := thing["owner"].(map[string]interface{}); owner["__typename"] != "User" {
+					t.Errorf("owner __typename = %v, want User", owner["__typename"])
+				}
+			default:
+				t.Fatalf("thing __typename = %v", thing["__typename"])
+			}
+			if len(thing) != len(wantKeys) {
+				t.Errorf("%v has fields %v, want %v", typename, thing, wantKeys)
+			}
+		}
+	}
+	if !seen["User"] || !seen["Bot"] {
+		t.Errorf("got union members %v, want both", seen)
+	}
+}
+
+func TestMockDirectives(t *testing.T) {
+	m := newMock(t, nil)
+	const query = `query ($a: Boolean!, $b: Boolean!) {
+		me {
+			id @include(if: $a)
+			name @skip(if: $a)
+			... on User @include(if: $b) { age }
+			...F @skip(if: $b)
+		}
+	}
+	fragment F on User { role }`
+
+	tests := []struct {
+		a, b bool
+		want []string
+	}{
+		{a: true, b: true, want: []string{"age", "id"}},
+		{a: true, b: false, want: []string{"id", "role"}},
+		{a: false, b: true, want: []string{"age", "name"}},
+		{a: false, b: false, want: []string{"name", "role"}},
+	}
+	for _, tc := range tests {
+		data := mockData(t, m, query, map[string]interface{}{"a": tc.a, "b": tc.b})
+		var got []string
+		for k := range data["me"].(map[string]interface{}) {
+			got = append(got, k)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("with a = %v, b = %v: got fields %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestMockInvalidQuery(t *testing.T) {
+	resp := newMock(t, nil).Respond(&gqltest.Request{Query: `query { unknown }`})
+	if len(resp.Errors) == 0 {
+		t.Errorf("Respond() with an invalid query returned no errors")
+	}
+}
@@ -0,0 +1,289 @@
+package gqltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Cassette is a list of recorded interactions, stored as a JSON file.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and response pair.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a recorded GraphQL request.
+type RecordedRequest struct {
+	OperationName string                    `json:"operationName,omitempty"`
+	Query         string                    `json:"query"`
+	Variables     map[string]interface{}    `json:"variables,omitempty"`
+	Header        http.Header               `json:"header,omitempty"`
+	Uploads       map[string]RecordedUpload `json:"uploads,omitempty"`
+}
+
+// RecordedUpload contains metadata about a recorded upload. The upload
+// contents aren't recorded.
+type RecordedUpload struct {
+	Filename string `json:"filename,omitempty"`
+	MIMEType string `json:"mimeType,omitempty"`
+	Size     int    `json:"size"`
+}
+
+// RecordedResponse is a recorded HTTP response.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	// Raw response body, as sent by the server. It may be compressed, as
+	// indicated by the Content-Encoding header field. Stored as base64 in
+	// the cassette.
+	Body []byte `json:"body"`
+}
+
+// MatchRule checks whether an incoming request matches a recorded request.
+type MatchRule func(recorded, req *RecordedRequest) bool
+
+// MatchOperationName compares operation names.
+func MatchOperationName(recorded, req *RecordedRequest) bool {
+	return recorded.OperationName == req.OperationName
+}
+
+// MatchQuery compares queries, ignoring differences in whitespace.
+func MatchQuery(recorded, req *RecordedRequest) bool {
+	return strings.Join(strings.Fields(recorded.Query), " ") == strings.Join(strings.Fields(req.Query), " ")
+}
+
+// MatchVariables compares variables once encoded to JSON.
+func MatchVariables(recorded, req *RecordedRequest) bool {
+	return jsonEqual(recorded.Variables, req.Variables)
+}
+
+// MatchHeader returns a rule comparing the values of a header field.
+func MatchHeader(k string) MatchRule {
+	return func(recorded, req *RecordedRequest) bool {
+		return strings.Join(recorded.Header.Values(k), ",") == strings.Join(req.Header.Values(k), ",")
+	}
+}
+
+// RecorderMode indicates whether a Recorder records or replays interactions.
+type RecorderMode int
+
+const (
+	// ModeReplay replays interactions from an existing cassette.
+	ModeReplay RecorderMode = iota
+	// ModeRecord sends requests to the real server and records them.
+	ModeRecord
+)
+
+// RecorderOptions contains options for NewRecorder.
+type RecorderOptions struct {
+	Mode RecorderMode
+	// Rules used to match requests on replay. Defaults to MatchOperationName,
+	// MatchQuery and MatchVariables.
+	Match []MatchRule
+	// Header fields whose values are redacted in the cassette. Defaults to
+	// Authorization, Proxy-Authorization, Cookie and Set-Cookie.
+	RedactHeaders []string
+	// Transport used to send requests in record mode. Defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+const redacted = "REDACTED"
+
+// Recorder is an http.RoundTripper which records GraphQL traffic to a
+// cassette file, or replays it from a cassette file.
+type Recorder struct {
+	filename string
+	options  RecorderOptions
+
+	mutex    sync.Mutex
+	cassette Cassette
+	used     map[*Interaction]bool
+}
+
+// NewRecorder creates a new recorder backed by the specified cassette file.
+//
+// In replay mode, the cassette file is loaded and must exist. In record mode,
+// the cassette file is written by Save.
+//
+// If options is nil, default options are used.
+func NewRecorder(filename string, options *RecorderOptions) (*Recorder, error) {
+	r := &Recorder{
+		filename: filename,
+		used:     make(map[*Interaction]bool),
+	}
+	if options != nil {
+		r.options = *options
+	}
+	if r.options.Match == nil {
+		r.options.Match = []MatchRule{MatchOperationName, MatchQuery, MatchVariables}
+	}
+	if r.options.RedactHeaders == nil {
+		r.options.RedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+	}
+	if r.options.Transport == nil {
+		r.options.Transport = http.DefaultTransport
+	}
+
+	if r.options.Mode == ModeReplay {
+		b, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("gqltest: failed to load cassette: %v", err)
+		}
+		if err := json.Unmarshal(b, &r.cassette); err != nil {
+			return nil, fmt.Errorf("gqltest: failed to decode cassette %q: %v", filename, err)
+		}
+	}
+
+	return r, nil
+}
+
+// HTTPClient returns an HTTP client using the recorder as its transport.
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("gqltest: failed to read request body: %v", err)
+		}
+	}
+
+	gqlReq, err := readRequest(req.Header, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("gqltest: invalid request: %v", err)
+	}
+	recReq := r.recordRequest(gqlReq)
+
+	if r.options.Mode == ModeReplay {
+		return r.replay(req, recReq)
+	}
+
+	outReq := req.Clone(req.Context())
+	outReq.Body = io.NopCloser(bytes.NewReader(body))
+	resp, err := r.options.Transport.RoundTrip(outReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("gqltest: failed to read response body: %v", err)
+	}
+
+	r.mutex.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, &Interaction{
+		Request: *recReq,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     r.redact(resp.Header),
+			Body:       respBody,
+		},
+	})
+	r.mutex.Unlock()
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, recReq *RecordedRequest) (*http.Response, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, interaction := range r.cassette.Interactions {
+		if r.used[interaction] || !r.match(&interaction.Request, recReq) {
+			continue
+		}
+		r.used[interaction] = true
+
+		rec := &interaction.Response
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", rec.StatusCode, http.StatusText(rec.StatusCode)),
+			StatusCode:    rec.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        rec.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(rec.Body)),
+			ContentLength: int64(len(rec.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	name := recReq.OperationName
+	if name == "" {
+		name = "<anonymous>"
+	}
+	return nil, fmt.Errorf("gqltest: no recorded interaction in cassette %q matches operation %v", r.filename, name)
+}
+
+func (r *Recorder) match(recorded, req *RecordedRequest) bool {
+	for _, rule := range r.options.Match {
+		if !rule(recorded, req) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Recorder) recordRequest(req *Request) *RecordedRequest {
+	rec := &RecordedRequest{
+		OperationName: req.OperationName,
+		Query:         req.Query,
+		Variables:     req.Variables,
+		Header:        r.redact(req.Header),
+	}
+	if len(req.Uploads) > 0 {
+		rec.Uploads = make(map[string]RecordedUpload)
+		for k, upload := range req.Uploads {
+			rec.Uploads[k] = RecordedUpload{
+				Filename: upload.Filename,
+				MIMEType: upload.MIMEType,
+				Size:     len(upload.Body),
+			}
+		}
+	}
+	return rec
+}
+
+func (r *Recorder) redact(h http.Header) http.Header {
+	h = h.Clone()
+	for _, k := range r.options.RedactHeaders {
+		if _, ok := h[http.CanonicalHeaderKey(k)]; ok {
+			h.Set(k, redacted)
+		}
+	}
+	return h
+}
+
+// Save writes the recorded interactions to the cassette file.
+func (r *Recorder) Save() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	b, err := json.MarshalIndent(&r.cassette, "", "\t")
+	if err != nil {
+		return fmt.Errorf("gqltest: failed to encode cassette: %v", err)
+	}
+	if err := os.WriteFile(r.filename, append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("gqltest: failed to save cassette: %v", err)
+	}
+	return nil
+}
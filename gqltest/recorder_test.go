@@ -0,0 +1,238 @@
+package gqltest_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~emersion/gqlclient"
+	"git.sr.ht/~emersion/gqlclient/gqltest"
+)
+
+func TestRecorderCompressedBody(t *testing.T) {
+	var body bytes.Buffer
+	w, err := gqlclient.Gzip.NewWriter(&body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(`{"data":{"hello":"world"}}`))
+	w.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(body.Bytes())
+	}))
+	defer srv.Close()
+
+	filename := filepath.Join(t.TempDir(), "cassette.json")
+	execute := func(rec *gqltest.Recorder) string {
+		c := gqlclient.New(srv.URL, rec.HTTPClient())
+		c.ResponseEncodings = []*gqlclient.Encoding{gqlclient.Gzip}
+
+		var data struct {
+			Hello string
+		}
+		if err := c.Execute(context.Background(), gqlclient.NewOperation("query { hello }"), &data); err != nil {
+			t.Fatalf("Execute() = %v", err)
+		}
+		return data.Hello
+	}
+
+	rec, err := gqltest.NewRecorder(filename, &gqltest.RecorderOptions{Mode: gqltest.ModeRecord})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := execute(rec); got != "world" {
+		t.Errorf("recorded hello = %q, want %q", got, "world")
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.Close()
+
+	rec, err = gqltest.NewRecorder(filename, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := execute(rec); got != "world" {
+		t.Errorf("replayed hello = %q, want %q", got, "world")
+	}
+}
+
+// recordCassette records interactions with a fake server, using fn to send
+// requests with additional header fields, and returns the cassette filename.
+func recordCassette(t *testing.T, srv *gqltest.Server, header http.Header, fn func(c *gqlclient.Client)) string {
+	filename := filepath.Join(t.TempDir(), "cassette.json")
+
+	rec, err := gqltest.NewRecorder(filename, &gqltest.RecorderOptions{Mode: gqltest.ModeRecord})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hc := &http.Client{Transport: headerTransport(header).wrap(rec)}
+	fn(gqlclient.New(srv.URL, hc))
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func executeString(c *gqlclient.Client, op *gqlclient.Operation) (string, error) {
+	var data struct {
+		V string
+	}
+	err := c.Execute(context.Background(), op, &data)
+	return data.V, err
+}
+
+func newCountingServer() *gqltest.Server {
+	srv := gqltest.NewServer()
+	n := 0
+	srv.HandleFunc(func(req *gqltest.Request) *gqltest.Response {
+		n++
+		return &gqltest.Response{
+			Data:   map[string]interface{}{"v": fmt.Sprintf("%v-%v", req.OperationName, n)},
+			Header: http.Header{"Set-Cookie": {"session=secret"}, "X-Request-Id": {strconv.Itoa(n)}},
+		}
+	})
+	return srv
+}
+
+func TestRecorderRedact(t *testing.T) {
+	srv := newCountingServer()
+	defer srv.Close()
+
+	header := http.Header{"Authorization": {"Bearer secret"}, "Cookie": {"session=secret"}, "X-Trace": {"1"}}
+	filename := recordCassette(t, srv, header, func(c *gqlclient.Client) {
+		if _, err := executeString(c, gqlclient.NewOperation(`query a { v }`)); err != nil {
+			t.Fatalf("Execute() = %v", err)
+		}
+	})
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, []byte("secret")) {
+		t.Errorf("cassette contains secrets:\n%s", b)
+	}
+	var cassette gqltest.Cassette
+	if err := json.Unmarshal(b, &cassette); err != nil {
+		t.Fatal(err)
+	}
+	if len(cassette.Interactions) != 1 {
+		t.Fatalf("got %v interactions, want 1", len(cassette.Interactions))
+	}
+	interaction := cassette.Interactions[0]
+	reqHeader, respHeader := interaction.Request.Header, interaction.Response.Header
+	for _, v := range []string{reqHeader.Get("Authorization"), reqHeader.Get("Cookie"), respHeader.Get("Set-Cookie")} {
+		if v != "REDACTED" {
+			t.Errorf("got header value %q, want it redacted", v)
+		}
+	}
+	if v := reqHeader.Get("X-Trace"); v != "1" {
+		t.Errorf("X-Trace = %q, want %q", v, "1")
+	}
+	if v := respHeader.Get("X-Request-Id"); v != "1" {
+		t.Errorf("X-Request-Id = %q, want %q", v, "1")
+	}
+}
+
+func TestRecorderReplay(t *testing.T) {
+	srv := newCountingServer()
+	defer srv.Close()
+
+	filename := recordCassette(t, srv, nil, func(c *gqlclient.Client) {
+		for _, name := range []string{"a", "a", "b"} {
+			if _, err := executeString(c, gqlclient.NewOperation(`query `+name+` { v }`)); err != nil {
+				t.Fatalf("Execute() = %v", err)
+			}
+		}
+	})
+	srv.Close()
+
+	rec, err := gqltest.NewRecorder(filename, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := gqlclient.New("http://example.invalid", rec.HTTPClient())
+
+	// Each interaction is replayed once, in order
+	for _, want := range []string{"b-3", "a-1", "a-2"} {
+		name := want[:1]
+		got, err := executeString(c, gqlclient.NewOperation(`query `+name+` {
+			v
+		}`))
+		if err != nil {
+			t.Fatalf("Execute(%v) = %v", name, err)
+		}
+		if got != want {
+			t.Errorf("Execute(%v) = %q, want %q", name, got, want)
+		}
+	}
+
+	for _, query := range []string{`query a { v }`, `query c { v }`} {
+		if _, err := executeString(c, gqlclient.NewOperation(query)); err == nil || !strings.Contains(err.Error(), "no recorded interaction") {
+			t.Errorf("Execute(%q) = %v, want an unrecorded request error", query, err)
+		}
+	}
+}
+
+func TestRecorderMatch(t *testing.T) {
+	srv := newCountingServer()
+	defer srv.Close()
+
+	filename := recordCassette(t, srv, http.Header{"X-Tenant": {"foo"}}, func(c *gqlclient.Client) {
+		op := gqlclient.NewOperation(`query a($id: ID!) { v(id: $id) }`)
+		op.Var("id", "1")
+		if _, err := executeString(c, op); err != nil {
+			t.Fatalf("Execute() = %v", err)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		match  []gqltest.MatchRule
+		id     string
+		tenant string
+		ok     bool
+	}{
+		{name: "default", id: "1", ok: true},
+		{name: "default with other variables", id: "2", ok: false},
+		{name: "operation name only", match: []gqltest.MatchRule{gqltest.MatchOperationName}, id: "2", ok: true},
+		{name: "header", match: []gqltest.MatchRule{gqltest.MatchOperationName, gqltest.MatchHeader("X-Tenant")}, id: "1", tenant: "foo", ok: true},
+		{name: "other header", match: []gqltest.MatchRule{gqltest.MatchOperationName, gqltest.MatchHeader("X-Tenant")}, id: "1", tenant: "bar", ok: false},
+	}
+	for _, tc := range tests {
+		rec, err := gqltest.NewRecorder(filename, &gqltest.RecorderOptions{Match: tc.match})
+		if err != nil {
+			t.Fatal(err)
+		}
+		hc := rec.HTTPClient()
+		if tc.tenant != "" {
+			hc.Transport = headerTransport{"X-Tenant": {tc.tenant}}.wrap(rec)
+		}
+
+		op := gqlclient.NewOperation(`query a($id: ID!) { v(id: $id) }`)
+		op.Var("id", tc.id)
+		_, err = executeString(gqlclient.New("http://example.invalid", hc), op)
+		if ok := err == nil; ok != tc.ok {
+			t.Errorf("%v: Execute() = %v, want match = %v", tc.name, err, tc.ok)
+		}
+	}
+}
+
+func TestRecorderMissingCassette(t *testing.T) {
+	if _, err := gqltest.NewRecorder(filepath.Join(t.TempDir(), "missing.json"), nil); err == nil {
+		t.Errorf("NewRecorder() with a missing cassette succeeded")
+	}
+}
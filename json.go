@@ -0,0 +1,28 @@
+package gqlclient
+
+import (
+	"encoding/json"
+)
+
+// JSON is a raw JSON value.
+//
+// The zero value is encoded as null.
+type JSON json.RawMessage
+
+// MarshalJSON implements json.Marshaler.
+func (v JSON) MarshalJSON() ([]byte, error) {
+	if len(v) == 0 {
+		return []byte("null"), nil
+	}
+	return json.RawMessage(v).MarshalJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *JSON) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*v = nil
+		return nil
+	}
+	*v = append((*v)[0:0], b...)
+	return nil
+}
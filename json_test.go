@@ -0,0 +1,47 @@
+package gqlclient
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		A JSON `json:"a"`
+		B JSON `json:"b"`
+		C JSON `json:"c"`
+	}
+	const in = `{"a":{"x":[1,"two",null]},"b":null,"c":"s"}`
+	if err := json.Unmarshal([]byte(in), &v); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if string(v.A) != `{"x":[1,"two",null]}` {
+		t.Errorf("a = %s", v.A)
+	}
+	if v.B != nil {
+		t.Errorf("b = %s, want nil", v.B)
+	}
+
+	b, err := json.Marshal(&v)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if string(b) != in {
+		t.Errorf("Marshal() = %s, want %s", b, in)
+	}
+
+	// Values aren't shared with the decoded buffer
+	buf := []byte(`"x"`)
+	var j JSON
+	if err := json.Unmarshal(buf, &j); err != nil {
+		t.Fatal(err)
+	}
+	buf[1] = 'y'
+	if string(j) != `"x"` {
+		t.Errorf("value = %s, want %s", j, `"x"`)
+	}
+
+	if _, err := json.Marshal(JSON(`{invalid`)); err == nil {
+		t.Errorf("Marshal() with invalid JSON succeeded")
+	}
+}
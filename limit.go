@@ -0,0 +1,124 @@
+package gqlclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// limitReader fails with a LimitError when more than max bytes are read.
+type limitReader struct {
+	r   io.Reader
+	n   int64
+	max int64
+}
+
+// Read reads one byte more than the limit to detect oversized bodies, but
+// never returns bytes past the limit.
+func (lr *limitReader) Read(b []byte) (int, error) {
+	if lr.n > lr.max {
+		return 0, &LimitError{Limit: "MaxResponseSize", Max: lr.max}
+	}
+	if int64(len(b)) > lr.max-lr.n+1 {
+		b = b[:lr.max-lr.n+1]
+	}
+	n, err := lr.r.Read(b)
+	lr.n += int64(n)
+	if lr.n > lr.max {
+		return n - int(lr.n-lr.max), &LimitError{Limit: "MaxResponseSize", Max: lr.max}
+	}
+	return n, err
+}
+
+// depthReader fails with a LimitError when the JSON data read from it is
+// nested deeper than max. The bytes starting at the offending delimiter are
+// withheld, so that the JSON value is never seen complete.
+type depthReader struct {
+	r        io.Reader
+	max      int
+	depth    int
+	inString bool
+	escape   bool
+	err      error
+}
+
+func (dr *depthReader) Read(b []byte) (int, error) {
+	if dr.err != nil {
+		return 0, dr.err
+	}
+	n, err := dr.r.Read(b)
+	for i, ch := range b[:n] {
+		if dr.inString {
+			switch {
+			case dr.escape:
+				dr.escape = false
+			case ch == '\\':
+				dr.escape = true
+			case ch == '"':
+				dr.inString = false
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			dr.inString = true
+		case '{', '[':
+			dr.depth++
+			if dr.depth > dr.max {
+				dr.err = &LimitError{Limit: "MaxResponseDepth", Max: int64(dr.max)}
+				return i, dr.err
+			}
+		case '}', ']':
+			dr.depth--
+		}
+	}
+	return n, err
+}
+
+// limitBody wraps a response body to enforce the client limits.
+func (c *Client) limitBody(r io.Reader) io.Reader {
+	if c.MaxResponseSize > 0 {
+		r = &limitReader{r: r, max: c.MaxResponseSize}
+	}
+	if c.MaxResponseDepth > 0 {
+		r = &depthReader{r: r, max: c.MaxResponseDepth}
+	}
+	return r
+}
+
+// decodeErrorList decodes a list of GraphQL errors one element at a time,
+// appending them to errs. It fails as soon as errs holds more than max
+// elements, without reading the rest of the list.
+func decodeErrorList(dec *json.Decoder, errs *[]Error, max int) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	} else if tok != json.Delim('[') {
+		return fmt.Errorf("expected list of errors, got %v", tok)
+	}
+	for dec.More() {
+		if max > 0 && len(*errs) >= max {
+			return &LimitError{Limit: "MaxResponseErrors", Max: int64(max)}
+		}
+		var e Error
+		if err := dec.Decode(&e); err != nil {
+			return err
+		}
+		*errs = append(*errs, e)
+	}
+	return expectDelim(dec, ']')
+}
+
+// asLimitError returns the LimitError wrapped in err, if any.
+func asLimitError(err error) *LimitError {
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		return limitErr
+	}
+	return nil
+}
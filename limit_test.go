@@ -0,0 +1,136 @@
+package gqlclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestLimitReader(t *testing.T) {
+	tests := []struct {
+		body    string
+		max     int64
+		want    string
+		wantErr bool
+	}{
+		{body: "hello", max: 10, want: "hello"},
+		{body: "hello", max: 5, want: "hello"},
+		{body: "hello world", max: 5, want: "hello", wantErr: true},
+		{body: "", max: 1, want: ""},
+	}
+	for _, tc := range tests {
+		for _, oneByte := range []bool{false, true} {
+			var r io.Reader = strings.NewReader(tc.body)
+			if oneByte {
+				r = iotest.OneByteReader(r)
+			}
+			b, err := io.ReadAll(&limitReader{r: r, max: tc.max})
+			if string(b) != tc.want {
+				t.Errorf("limitReader(%q, %v) returned %q, want %q", tc.body, tc.max, b, tc.want)
+			}
+			var limitErr *LimitError
+			if tc.wantErr != errors.As(err, &limitErr) {
+				t.Errorf("limitReader(%q, %v) = %v, want limit error: %v", tc.body, tc.max, err, tc.wantErr)
+			}
+		}
+	}
+}
+
+func TestDepthReader(t *testing.T) {
+	tests := []struct {
+		body    string
+		max     int
+		want    string
+		wantErr bool
+	}{
+		{body: `{"a":[1]}`, max: 2, want: `{"a":[1]}`},
+		{body: `{"a":[1]}`, max: 1, want: `{"a":`, wantErr: true},
+		{body: `{"a":"{[{["}`, max: 1, want: `{"a":"{[{["}`},
+		{body: `{"a":"\"{"}`, max: 1, want: `{"a":"\"{"}`},
+		{body: `[[],[],[]]`, max: 2, want: `[[],[],[]]`},
+		{body: `[[],[[]]]`, max: 2, want: `[[],[`, wantErr: true},
+	}
+	for _, tc := range tests {
+		for _, oneByte := range []bool{false, true} {
+			var r io.Reader = strings.NewReader(tc.body)
+			if oneByte {
+				r = iotest.OneByteReader(r)
+			}
+			b, err := io.ReadAll(&depthReader{r: r, max: tc.max})
+			if string(b) != tc.want {
+				t.Errorf("depthReader(%q, %v) returned %q, want %q", tc.body, tc.max, b, tc.want)
+			}
+			var limitErr *LimitError
+			if tc.wantErr != errors.As(err, &limitErr) {
+				t.Errorf("depthReader(%q, %v) = %v, want limit error: %v", tc.body, tc.max, err, tc.wantErr)
+			}
+		}
+	}
+}
+
+func TestClientLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		configure func(c *Client)
+		wantLimit string
+	}{
+		{
+			name:      "depth",
+			body:      `{"data":{"a":{"b":{"c":[1]}}}}`,
+			configure: func(c *Client) { c.MaxResponseDepth = 3 },
+			wantLimit: "MaxResponseDepth",
+		},
+		{
+			name:      "depth within limit",
+			body:      `{"data":{"a":{"b":{"c":[1]}}}}`,
+			configure: func(c *Client) { c.MaxResponseDepth = 5 },
+		},
+		{
+			name:      "size",
+			body:      `{"data":{"a":{"b":{"c":[1]}}}}  `,
+			configure: func(c *Client) { c.MaxResponseSize = 20 },
+			wantLimit: "MaxResponseSize",
+		},
+		{
+			name:      "errors",
+			body:      `{"errors":[{"message":"a"},{"message":"b"},{"message":"c"}],"data":null}`,
+			configure: func(c *Client) { c.MaxResponseErrors = 2 },
+			wantLimit: "MaxResponseErrors",
+		},
+		{
+			// The limit triggers before the malformed third error is read
+			name:      "errors stops early",
+			body:      `{"errors":[{"message":"a"},{"message":"b"},{"message":`,
+			configure: func(c *Client) { c.MaxResponseErrors = 2 },
+			wantLimit: "MaxResponseErrors",
+		},
+		{
+			name:      "errors within limit",
+			body:      `{"errors":[{"message":"a"},{"message":"b"}],"data":null}`,
+			configure: func(c *Client) { c.MaxResponseErrors = 2 },
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := newTestClient(t, tc.body)
+			tc.configure(c)
+
+			var data interface{}
+			err := c.Execute(context.Background(), NewOperation("query { a }"), &data)
+			var limitErr *LimitError
+			if errors.As(err, &limitErr) {
+				if limitErr.Limit != tc.wantLimit {
+					t.Errorf("Execute() = %v, want limit %q", err, tc.wantLimit)
+				}
+			} else if tc.wantLimit != "" {
+				t.Errorf("Execute() = %v, want limit %q", err, tc.wantLimit)
+			}
+		})
+	}
+}
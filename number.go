@@ -0,0 +1,157 @@
+package gqlclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// unmarshalNumber decodes a JSON number or a JSON string containing a number.
+func unmarshalNumber(b []byte) (s string, null bool, err error) {
+	if string(b) == "null" {
+		return "", true, nil
+	}
+
+	var n json.Number
+	if strings.HasPrefix(string(b), `"`) {
+		var str string
+		if err := json.Unmarshal(b, &str); err != nil {
+			return "", false, err
+		}
+		n = json.Number(str)
+	} else if err := json.Unmarshal(b, &n); err != nil {
+		return "", false, err
+	}
+	return n.String(), false, nil
+}
+
+// BigInt is an arbitrary-precision integer.
+//
+// BigInt values are encoded as JSON strings, and can be decoded from JSON
+// strings or numbers. A nil value is encoded as null.
+type BigInt struct {
+	*big.Int
+}
+
+// MarshalJSON implements json.Marshaler.
+func (i BigInt) MarshalJSON() ([]byte, error) {
+	var v interface{}
+	if i.Int != nil {
+		v = i.String()
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (i *BigInt) UnmarshalJSON(b []byte) error {
+	s, null, err := unmarshalNumber(b)
+	if err != nil {
+		return err
+	} else if null {
+		i.Int = nil
+		return nil
+	}
+
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return fmt.Errorf("gqlclient: invalid BigInt %q", s)
+	}
+	i.Int = v
+	return nil
+}
+
+// Decimal is an arbitrary-precision decimal number.
+//
+// Decimal values are encoded as JSON strings, and can be decoded from JSON
+// strings or numbers. A nil value is encoded as null.
+type Decimal struct {
+	*big.Rat
+}
+
+// decimalRegexp matches decimal numbers, with an optional exponent.
+var decimalRegexp = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?$`)
+
+// ParseDecimal parses a decimal number, e.g. "-12.345" or "1.5e3".
+func ParseDecimal(s string) (Decimal, error) {
+	if !decimalRegexp.MatchString(s) {
+		return Decimal{}, fmt.Errorf("gqlclient: invalid Decimal %q", s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Decimal{}, fmt.Errorf("gqlclient: invalid Decimal %q", s)
+	}
+	return Decimal{r}, nil
+}
+
+// String formats the decimal number without loss of precision.
+func (d Decimal) String() string {
+	if d.Rat == nil {
+		return "<nil>"
+	}
+	s, err := formatDecimal(d.Rat)
+	if err != nil {
+		return d.Rat.String()
+	}
+	return s
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	var v interface{}
+	if d.Rat != nil {
+		s, err := formatDecimal(d.Rat)
+		if err != nil {
+			return nil, err
+		}
+		v = s
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	s, null, err := unmarshalNumber(b)
+	if err != nil {
+		return err
+	} else if null {
+		d.Rat = nil
+		return nil
+	}
+
+	*d, err = ParseDecimal(s)
+	return err
+}
+
+// formatDecimal formats a rational number as a decimal number. It fails if
+// the number has no finite decimal representation.
+func formatDecimal(r *big.Rat) (string, error) {
+	// The denominator must be of the form 2^a * 5^b
+	denom := new(big.Int).Set(r.Denom())
+	var twos, fives int
+	two, five := big.NewInt(2), big.NewInt(5)
+	mod := new(big.Int)
+	for {
+		if q, m := new(big.Int).QuoRem(denom, two, mod); m.Sign() == 0 {
+			denom = q
+			twos++
+			continue
+		}
+		if q, m := new(big.Int).QuoRem(denom, five, mod); m.Sign() == 0 {
+			denom = q
+			fives++
+			continue
+		}
+		break
+	}
+	if denom.Cmp(big.NewInt(1)) != 0 {
+		return "", fmt.Errorf("gqlclient: %v has no finite decimal representation", r)
+	}
+
+	prec := twos
+	if fives > prec {
+		prec = fives
+	}
+	return r.FloatString(prec), nil
+}
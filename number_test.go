@@ -0,0 +1,112 @@
+package gqlclient
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestDecimalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: `"-12.345"`, want: `"-12.345"`},
+		{in: `-12.345`, want: `"-12.345"`},
+		{in: `"1.5e3"`, want: `"1500"`},
+		{in: `1e-3`, want: `"0.001"`},
+		{in: `"0.10"`, want: `"0.1"`},
+		{in: `"12345678901234567890.0123456789"`, want: `"12345678901234567890.0123456789"`},
+		{in: `"0"`, want: `"0"`},
+		{in: `null`, want: `null`},
+		{in: `"abc"`, wantErr: true},
+		{in: `true`, wantErr: true},
+		{in: `"1/3"`, wantErr: true},
+		{in: `"3/4"`, wantErr: true},
+		{in: `"0x10"`, wantErr: true},
+		{in: `"1_000"`, wantErr: true},
+		{in: `"Inf"`, wantErr: true},
+		{in: `""`, wantErr: true},
+	}
+	for _, tc := range tests {
+		var d Decimal
+		err := json.Unmarshal([]byte(tc.in), &d)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("Unmarshal(%v) = %v, want an error", tc.in, d)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("Unmarshal(%v) = %v", tc.in, err)
+			continue
+		}
+		b, err := json.Marshal(d)
+		if err != nil {
+			t.Errorf("Marshal(%v) = %v", tc.in, err)
+		} else if string(b) != tc.want {
+			t.Errorf("Marshal(Unmarshal(%v)) = %v, want %v", tc.in, string(b), tc.want)
+		}
+	}
+}
+
+func TestFormatDecimal(t *testing.T) {
+	tests := []struct {
+		num, denom int64
+		want       string
+		wantErr    bool
+	}{
+		{num: 1, denom: 8, want: "0.125"},
+		{num: 1, denom: 20, want: "0.05"},
+		{num: -7, denom: 2, want: "-3.5"},
+		{num: 3, denom: 1, want: "3"},
+		{num: 1, denom: 3, wantErr: true},
+		{num: 1, denom: 6, wantErr: true},
+	}
+	for _, tc := range tests {
+		r := big.NewRat(tc.num, tc.denom)
+		got, err := formatDecimal(r)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("formatDecimal(%v) = %q, want an error", r, got)
+			}
+			if _, err := json.Marshal(Decimal{r}); err == nil {
+				t.Errorf("Marshal(%v) succeeded", r)
+			}
+		} else if err != nil {
+			t.Errorf("formatDecimal(%v) = %v", r, err)
+		} else if got != tc.want {
+			t.Errorf("formatDecimal(%v) = %q, want %q", r, got, tc.want)
+		}
+	}
+}
+
+func TestBigIntJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: `"123456789012345678901234567890"`, want: `"123456789012345678901234567890"`},
+		{in: `-42`, want: `"-42"`},
+		{in: `null`, want: `null`},
+		{in: `"1.5"`, wantErr: true},
+		{in: `"abc"`, wantErr: true},
+	}
+	for _, tc := range tests {
+		var i BigInt
+		err := json.Unmarshal([]byte(tc.in), &i)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("Unmarshal(%v) = %v, want an error", tc.in, i)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("Unmarshal(%v) = %v", tc.in, err)
+			continue
+		}
+		if b, err := json.Marshal(i); err != nil || string(b) != tc.want {
+			t.Errorf("Marshal(Unmarshal(%v)) = %s, %v, want %v", tc.in, b, err, tc.want)
+		}
+	}
+}
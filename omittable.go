@@ -0,0 +1,62 @@
+package gqlclient
+
+import (
+	"encoding/json"
+)
+
+// Omittable is an optional value, which distinguishes between an omitted
+// value, an explicit null and a set value.
+//
+// The zero value is omitted. Omitted values are encoded as null by
+// MarshalJSON: structs containing Omittable fields must skip them when
+// IsOmitted returns true, as done by code generated by gqlclientgen.
+type Omittable[T any] struct {
+	value T
+	set   bool
+	null  bool
+}
+
+// OmittableValue returns an Omittable set to the specified value.
+func OmittableValue[T any](v T) Omittable[T] {
+	return Omittable[T]{value: v, set: true}
+}
+
+// OmittableNull returns an Omittable set to an explicit null.
+func OmittableNull[T any]() Omittable[T] {
+	return Omittable[T]{set: true, null: true}
+}
+
+// Get returns the value, and whether it is set and not null.
+func (o Omittable[T]) Get() (T, bool) {
+	return o.value, o.set && !o.null
+}
+
+// IsOmitted reports whether the value is omitted.
+func (o Omittable[T]) IsOmitted() bool {
+	return !o.set
+}
+
+// IsNull reports whether the value is an explicit null.
+func (o Omittable[T]) IsNull() bool {
+	return o.set && o.null
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o Omittable[T]) MarshalJSON() ([]byte, error) {
+	if !o.set || o.null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *Omittable[T]) UnmarshalJSON(b []byte) error {
+	var zero T
+	o.value = zero
+	o.set = true
+	o.null = string(b) == "null"
+	if o.null {
+		return nil
+	}
+	return json.Unmarshal(b, &o.value)
+}
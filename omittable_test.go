@@ -0,0 +1,74 @@
+package gqlclient
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOmittableMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		o    Omittable[int]
+		want string
+	}{
+		{name: "omitted", o: Omittable[int]{}, want: `null`},
+		{name: "null", o: OmittableNull[int](), want: `null`},
+		{name: "zero", o: OmittableValue(0), want: `0`},
+		{name: "value", o: OmittableValue(42), want: `42`},
+	}
+	for _, tc := range tests {
+		b, err := json.Marshal(tc.o)
+		if err != nil {
+			t.Errorf("%v: Marshal() = %v", tc.name, err)
+		} else if string(b) != tc.want {
+			t.Errorf("%v: Marshal() = %s, want %s", tc.name, b, tc.want)
+		}
+	}
+}
+
+func TestOmittableUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in          string
+		wantValue   string
+		wantOk      bool
+		wantOmitted bool
+		wantNull    bool
+	}{
+		{in: `{}`, wantOmitted: true},
+		{in: `{"v":null}`, wantNull: true},
+		{in: `{"v":""}`, wantValue: "", wantOk: true},
+		{in: `{"v":"a"}`, wantValue: "a", wantOk: true},
+	}
+	for _, tc := range tests {
+		var data struct {
+			V Omittable[string] `json:"v"`
+		}
+		if err := json.Unmarshal([]byte(tc.in), &data); err != nil {
+			t.Errorf("Unmarshal(%v) = %v", tc.in, err)
+			continue
+		}
+		v, ok := data.V.Get()
+		if v != tc.wantValue || ok != tc.wantOk {
+			t.Errorf("Unmarshal(%v): Get() = %q, %v, want %q, %v", tc.in, v, ok, tc.wantValue, tc.wantOk)
+		}
+		if data.V.IsOmitted() != tc.wantOmitted {
+			t.Errorf("Unmarshal(%v): IsOmitted() = %v, want %v", tc.in, data.V.IsOmitted(), tc.wantOmitted)
+		}
+		if data.V.IsNull() != tc.wantNull {
+			t.Errorf("Unmarshal(%v): IsNull() = %v, want %v", tc.in, data.V.IsNull(), tc.wantNull)
+		}
+	}
+
+	// A value decoded again is reset
+	o := OmittableValue("a")
+	if err := json.Unmarshal([]byte(`null`), &o); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if v, ok := o.Get(); ok || v != "" || !o.IsNull() {
+		t.Errorf("Unmarshal(null) = %q, %v, want an explicit null", v, ok)
+	}
+
+	if err := json.Unmarshal([]byte(`42`), &o); err == nil {
+		t.Errorf("Unmarshal(42) into Omittable[string] succeeded")
+	}
+}
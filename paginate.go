@@ -0,0 +1,203 @@
+package gqlclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// PaginateOptions contains options for Client.Paginate.
+type PaginateOptions struct {
+	// Backward fetches pages in reverse, using pageInfo.startCursor and
+	// pageInfo.hasPreviousPage instead of pageInfo.endCursor and
+	// pageInfo.hasNextPage. Nodes are returned from last to first.
+	Backward bool
+	// Name of the variable holding the cursor. Defaults to "after", or
+	// "before" if Backward is set.
+	CursorVar string
+	// Maximum number of pages to fetch. Zero means no limit.
+	MaxPages int
+}
+
+// Paginator iterates over the nodes of a Relay connection, fetching pages as
+// needed.
+//
+// See the Relay cursor connections specification for details:
+// https://relay.dev/graphql/connections.htm
+type Paginator struct {
+	client  *Client
+	ctx     context.Context
+	op      *Operation
+	path    []string
+	options PaginateOptions
+
+	pages   int
+	cursor  *string
+	cursors map[string]bool // cursors already used to fetch a page
+	more    bool
+	nodes   []json.RawMessage
+	err     error
+}
+
+// Paginate returns a paginator over the Relay connection at the specified
+// path.
+//
+// The path is a dot-separated list of field names relative to the data
+// returned by the server, e.g. "user.repositories". A field of the path
+// missing from the response is reported as an error. The connection must
+// select either edges { node } or nodes, along with the pageInfo cursor
+// fields. The operation is re-executed for each page with the cursor variable
+// updated.
+//
+// If options is nil, default options are used.
+func (c *Client) Paginate(ctx context.Context, op *Operation, path string, options *PaginateOptions) *Paginator {
+	var opts PaginateOptions
+	if options != nil {
+		opts = *options
+	}
+	if opts.CursorVar == "" {
+		if opts.Backward {
+			opts.CursorVar = "before"
+		} else {
+			opts.CursorVar = "after"
+		}
+	}
+
+	return &Paginator{
+		client:  c,
+		ctx:     ctx,
+		op:      op,
+		path:    strings.Split(path, "."),
+		options: opts,
+		cursors: make(map[string]bool),
+		more:    true,
+	}
+}
+
+// Next decodes the next node into v. It returns false when there are no more
+// nodes or an error occurs.
+func (p *Paginator) Next(v interface{}) bool {
+	if p.err != nil {
+		return false
+	}
+
+	for len(p.nodes) == 0 {
+		if !p.more || (p.options.MaxPages > 0 && p.pages >= p.options.MaxPages) {
+			return false
+		}
+		if err := p.fetch(); err != nil {
+			p.err = err
+			return false
+		}
+	}
+
+	if err := p.ctx.Err(); err != nil {
+		p.err = err
+		return false
+	}
+
+	node := p.nodes[0]
+	p.nodes = p.nodes[1:]
+	if err := json.Unmarshal(node, v); err != nil {
+		p.err = fmt.Errorf("failed to decode node: %v", err)
+		return false
+	}
+	return true
+}
+
+// Err returns the error which occurred during pagination, if any.
+func (p *Paginator) Err() error {
+	return p.err
+}
+
+func (p *Paginator) fetch() error {
+	if err := p.ctx.Err(); err != nil {
+		return err
+	}
+
+	op := p.op
+	if p.cursor != nil {
+		// A server returning the same cursor again would loop forever
+		if p.cursors[*p.cursor] {
+			return fmt.Errorf("gqlclient: pagination cursor %q returned twice", *p.cursor)
+		}
+		p.cursors[*p.cursor] = true
+
+		op = &Operation{query: p.op.query}
+		for k, v := range p.op.vars {
+			if k != p.options.CursorVar {
+				op.Var(k, v)
+			}
+		}
+		op.Var(p.options.CursorVar, *p.cursor)
+	}
+
+	var data json.RawMessage
+	if err := p.client.Execute(p.ctx, op, &data); err != nil {
+		return err
+	}
+	p.pages++
+
+	raw := data
+	for i, name := range p.path {
+		if string(raw) == "null" {
+			p.more = false
+			return nil
+		}
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return fmt.Errorf("failed to decode field %q: %v", name, err)
+		}
+		var ok bool
+		if raw, ok = obj[name]; !ok {
+			return fmt.Errorf("gqlclient: missing field %q in response", strings.Join(p.path[:i+1], "."))
+		}
+	}
+	if string(raw) == "null" {
+		p.more = false
+		return nil
+	}
+
+	var conn struct {
+		Edges []struct {
+			Node json.RawMessage
+		}
+		Nodes    []json.RawMessage
+		PageInfo struct {
+			HasNextPage     bool
+			HasPreviousPage bool
+			StartCursor     *string
+			EndCursor       *string
+		}
+	}
+	if err := json.Unmarshal(raw, &conn); err != nil {
+		return fmt.Errorf("failed to decode connection: %v", err)
+	}
+
+	if conn.Nodes != nil {
+		p.nodes = conn.Nodes
+	} else {
+		p.nodes = make([]json.RawMessage, len(conn.Edges))
+		for i, edge := range conn.Edges {
+			p.nodes[i] = edge.Node
+		}
+	}
+	if p.options.Backward {
+		for i, j := 0, len(p.nodes)-1; i < j; i, j = i+1, j-1 {
+			p.nodes[i], p.nodes[j] = p.nodes[j], p.nodes[i]
+		}
+	}
+
+	if p.options.Backward {
+		p.more = conn.PageInfo.HasPreviousPage
+		p.cursor = conn.PageInfo.StartCursor
+	} else {
+		p.more = conn.PageInfo.HasNextPage
+		p.cursor = conn.PageInfo.EndCursor
+	}
+	if p.cursor == nil {
+		p.more = false
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package gqlclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// newConnectionServer serves a connection over the nodes 1 to n, with pages
+// of two nodes. Cursors are node indices.
+func newConnectionServer(t *testing.T, n int) *Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var reqData struct {
+			Variables map[string]interface{}
+		}
+		if err := json.NewDecoder(req.Body).Decode(&reqData); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		start, end := 0, 2
+		if after, ok := reqData.Variables["after"].(string); ok {
+			i, _ := strconv.Atoi(after)
+			start, end = i+1, i+3
+		} else if before, ok := reqData.Variables["before"].(string); ok {
+			i, _ := strconv.Atoi(before)
+			start, end = i-2, i
+		} else if _, ok := reqData.Variables["last"]; ok {
+			start, end = n-2, n
+		}
+		if start < 0 {
+			start = 0
+		}
+		if end > n {
+			end = n
+		}
+
+		var edges []interface{}
+		for i := start; i < end; i++ {
+			edges = append(edges, map[string]interface{}{"node": i + 1})
+		}
+		data := map[string]interface{}{
+			"items": map[string]interface{}{
+				"edges": edges,
+				"pageInfo": map[string]interface{}{
+					"hasNextPage":     end < n,
+					"hasPreviousPage": start > 0,
+					"startCursor":     strconv.Itoa(start),
+					"endCursor":       strconv.Itoa(end - 1),
+				},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	t.Cleanup(srv.Close)
+	return New(srv.URL, nil)
+}
+
+func paginateAll(t *testing.T, p *Paginator) []int {
+	var nodes []int
+	var v int
+	for p.Next(&v) {
+		nodes = append(nodes, v)
+	}
+	if err := p.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	return nodes
+}
+
+func TestPaginate(t *testing.T) {
+	c := newConnectionServer(t, 5)
+	op := NewOperation("query { items { edges { node } pageInfo { hasNextPage endCursor } } }")
+
+	got := paginateAll(t, c.Paginate(context.Background(), op, "items", nil))
+	if want := []int{1, 2, 3, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("forward pagination = %v, want %v", got, want)
+	}
+
+	got = paginateAll(t, c.Paginate(context.Background(), op, "items", &PaginateOptions{MaxPages: 2}))
+	if want := []int{1, 2, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pagination with MaxPages = %v, want %v", got, want)
+	}
+}
+
+func TestPaginateBackward(t *testing.T) {
+	c := newConnectionServer(t, 5)
+	op := NewOperation("query ($last: Int) { items(last: $last) { edges { node } pageInfo { hasPreviousPage startCursor } } }")
+	op.Var("last", 2)
+
+	got := paginateAll(t, c.Paginate(context.Background(), op, "items", &PaginateOptions{Backward: true}))
+	if want := []int{5, 4, 3, 2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("backward pagination = %v, want %v", got, want)
+	}
+}
+
+func TestPaginateRepeatedCursor(t *testing.T) {
+	c := newTestClient(t, `{"data":{"items":{"nodes":[1],"pageInfo":{"hasNextPage":true,"endCursor":"x"}}}}`)
+	op := NewOperation("query { items { nodes pageInfo { hasNextPage endCursor } } }")
+
+	p := c.Paginate(context.Background(), op, "items", nil)
+	n := 0
+	var v int
+	for p.Next(&v) {
+		n++
+		if n > 10 {
+			t.Fatalf("pagination doesn't stop")
+		}
+	}
+	if p.Err() == nil {
+		t.Errorf("Err() = nil, want an error")
+	}
+	if n != 2 {
+		t.Errorf("got %v nodes, want 2", n)
+	}
+}
+
+func TestPaginatePath(t *testing.T) {
+	c := newTestClient(t, `{"data":{"user":null,"other":{"items":{"nodes":[1],"pageInfo":{"hasNextPage":false}}}}}`)
+	op := NewOperation("query { user { items { nodes } } }")
+
+	p := c.Paginate(context.Background(), op, "user.items", nil)
+	var v int
+	if p.Next(&v) || p.Err() != nil {
+		t.Errorf("pagination over a null object: Err() = %v, want an empty connection", p.Err())
+	}
+
+	for _, path := range []string{"items", "data.other.items"} {
+		p = c.Paginate(context.Background(), op, path, nil)
+		if p.Next(&v) || p.Err() == nil {
+			t.Errorf("pagination over missing path %q: Err() = nil, want an error", path)
+		}
+	}
+}
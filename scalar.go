@@ -0,0 +1,120 @@
+package gqlclient
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Scalar describes the Go representation of a GraphQL scalar.
+type Scalar struct {
+	// Fully qualified Go type name, e.g. "github.com/google/uuid.UUID". Types
+	// without a package path are predeclared Go types, e.g. "string".
+	GoType string
+	// If true, the zero value of the Go type is recognizable, so nullable
+	// values don't need a pointer.
+	RecognizableZero bool
+
+	// Marshal and Unmarshal encode and decode values to and from JSON. They
+	// are required for Go types which don't implement json.Marshaler and
+	// json.Unmarshaler. Values are passed as pointers to the Go type.
+	Marshal   func(v interface{}) ([]byte, error)
+	Unmarshal func(b []byte, v interface{}) error
+}
+
+var (
+	scalarsMutex sync.RWMutex
+	scalars      = map[string]*Scalar{
+		"Time": {
+			GoType:           "git.sr.ht/~emersion/gqlclient.Time",
+			RecognizableZero: true,
+		},
+		"Map": {
+			GoType:           "map[string]interface{}",
+			RecognizableZero: true,
+		},
+		"Upload": {
+			GoType: "git.sr.ht/~emersion/gqlclient.Upload",
+		},
+		"Any": {
+			GoType:           "interface{}",
+			RecognizableZero: true,
+		},
+		"Date": {
+			GoType:           "git.sr.ht/~emersion/gqlclient.Date",
+			RecognizableZero: true,
+		},
+		"Duration": {
+			GoType: "git.sr.ht/~emersion/gqlclient.Duration",
+		},
+		"UUID": {
+			GoType: "git.sr.ht/~emersion/gqlclient.UUID",
+		},
+		"Decimal": {
+			GoType:           "git.sr.ht/~emersion/gqlclient.Decimal",
+			RecognizableZero: true,
+		},
+		"BigInt": {
+			GoType:           "git.sr.ht/~emersion/gqlclient.BigInt",
+			RecognizableZero: true,
+		},
+		"JSON": {
+			GoType:           "git.sr.ht/~emersion/gqlclient.JSON",
+			RecognizableZero: true,
+		},
+	}
+)
+
+// RegisterScalar registers the Go representation of a GraphQL scalar,
+// replacing any previous registration for the same name.
+//
+// Registered codecs are used at runtime by MarshalScalar and UnmarshalScalar.
+// Code generated by gqlclientgen with the -C flag relies on them, so the
+// program using it must register the codec before executing operations.
+// Registering a scalar has no effect on gqlclientgen itself, which only knows
+// about the scalars built into this package: other scalars are mapped with
+// its -S and -C flags.
+func RegisterScalar(name string, s *Scalar) {
+	scalarsMutex.Lock()
+	defer scalarsMutex.Unlock()
+	scalars[name] = s
+}
+
+// LookupScalar returns the registered Go representation of a GraphQL scalar,
+// or nil if there is none.
+func LookupScalar(name string) *Scalar {
+	scalarsMutex.RLock()
+	defer scalarsMutex.RUnlock()
+	return scalars[name]
+}
+
+func lookupScalarCodec(name string) (*Scalar, error) {
+	s := LookupScalar(name)
+	if s == nil || s.Marshal == nil || s.Unmarshal == nil {
+		return nil, fmt.Errorf("gqlclient: no codec registered for scalar %q", name)
+	}
+	return s, nil
+}
+
+// MarshalScalar encodes a value with the codec registered for a GraphQL
+// scalar.
+//
+// It is used by code generated by gqlclientgen.
+func MarshalScalar(name string, v interface{}) ([]byte, error) {
+	s, err := lookupScalarCodec(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marshal(v)
+}
+
+// UnmarshalScalar decodes a value with the codec registered for a GraphQL
+// scalar.
+//
+// It is used by code generated by gqlclientgen.
+func UnmarshalScalar(name string, b []byte, v interface{}) error {
+	s, err := lookupScalarCodec(name)
+	if err != nil {
+		return err
+	}
+	return s.Unmarshal(b, v)
+}
@@ -0,0 +1,78 @@
+package gqlclient
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+)
+
+func TestScalarCodec(t *testing.T) {
+	RegisterScalar("TestURL", &Scalar{
+		GoType: "net/url.URL",
+		Marshal: func(v interface{}) ([]byte, error) {
+			return json.Marshal(v.(*url.URL).String())
+		},
+		Unmarshal: func(b []byte, v interface{}) error {
+			var s string
+			if err := json.Unmarshal(b, &s); err != nil {
+				return err
+			}
+			u, err := url.Parse(s)
+			if err != nil {
+				return err
+			}
+			*v.(*url.URL) = *u
+			return nil
+		},
+	})
+
+	u := &url.URL{Scheme: "https", Host: "example.org", Path: "/a b"}
+	b, err := MarshalScalar("TestURL", u)
+	if err != nil {
+		t.Fatalf("MarshalScalar() = %v", err)
+	}
+	if want := `"https://example.org/a%20b"`; string(b) != want {
+		t.Errorf("MarshalScalar() = %s, want %s", b, want)
+	}
+
+	var got url.URL
+	if err := UnmarshalScalar("TestURL", b, &got); err != nil {
+		t.Fatalf("UnmarshalScalar() = %v", err)
+	}
+	if got.String() != u.String() {
+		t.Errorf("UnmarshalScalar() = %v, want %v", &got, u)
+	}
+
+	if err := UnmarshalScalar("TestURL", []byte(`"%zz"`), &got); err == nil {
+		t.Errorf("UnmarshalScalar() with an invalid URL succeeded")
+	}
+}
+
+func TestScalarMissingCodec(t *testing.T) {
+	RegisterScalar("TestNoCodec", &Scalar{GoType: "string"})
+
+	// Built-in scalars are implemented with json.Marshaler and
+	// json.Unmarshaler, without a codec
+	for _, name := range []string{"TestUnknown", "TestNoCodec", "Time"} {
+		if _, err := MarshalScalar(name, new(string)); err == nil {
+			t.Errorf("MarshalScalar(%q) succeeded", name)
+		}
+		if err := UnmarshalScalar(name, []byte(`"x"`), new(string)); err == nil {
+			t.Errorf("UnmarshalScalar(%q) succeeded", name)
+		}
+	}
+}
+
+func TestRegisterScalar(t *testing.T) {
+	if LookupScalar("TestReplaced") != nil {
+		t.Fatalf("LookupScalar() returned an unregistered scalar")
+	}
+	RegisterScalar("TestReplaced", &Scalar{GoType: "string"})
+	RegisterScalar("TestReplaced", &Scalar{GoType: "int"})
+	if s := LookupScalar("TestReplaced"); s == nil || s.GoType != "int" {
+		t.Errorf("LookupScalar() = %+v, want the last registration", s)
+	}
+	if s := LookupScalar("UUID"); s == nil || s.GoType != "git.sr.ht/~emersion/gqlclient.UUID" {
+		t.Errorf("LookupScalar(UUID) = %+v", s)
+	}
+}
@@ -0,0 +1,275 @@
+package gqlclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Stream iterates over the elements of a list in a GraphQL response, decoding
+// them one at a time.
+//
+// A Stream is created with Client.ExecuteStream. Callers must close it when
+// done.
+type Stream struct {
+	resp      *http.Response
+	body      io.ReadCloser
+	dec       *json.Decoder
+	maxErrors int
+
+	depth  int // number of objects opened while walking to the list
+	inList bool
+	done   bool
+	errs   []Error
+	err    error
+}
+
+// ExecuteStream sends the operation to the GraphQL server and returns a
+// stream over the elements of the list at the specified path.
+//
+// The path is a dot-separated list of field names relative to the data
+// returned by the server, e.g. "orders.edges". Fields outside of the path are
+// discarded. If the data or an object along the path is null, the stream is
+// empty. A field missing from the response is reported as an error, since the
+// server returns all selected fields.
+//
+// GraphQL errors are collected while reading the response and are returned by
+// Stream.Err once the stream has been fully consumed.
+func (c *Client) ExecuteStream(ctx context.Context, op *Operation, path string) (*Stream, error) {
+	resp, body, err := c.do(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Stream{
+		resp:      resp,
+		body:      body,
+		dec:       json.NewDecoder(body),
+		maxErrors: c.MaxResponseErrors,
+	}
+	if err := s.start(strings.Split(path, ".")); err != nil {
+		body.Close()
+		return nil, decodeError(err)
+	}
+	return s, nil
+}
+
+func (s *Stream) start(path []string) error {
+	if err := expectDelim(s.dec, '{'); err != nil {
+		return err
+	}
+	for s.dec.More() {
+		key, err := readKey(s.dec)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "data":
+			found, err := s.walk(path)
+			if err != nil {
+				return err
+			}
+			if found {
+				s.inList = true
+				return nil
+			}
+			if err := s.unwind(); err != nil {
+				return err
+			}
+		case "errors":
+			if err := s.decodeErrors(); err != nil {
+				return err
+			}
+		default:
+			if err := skipValue(s.dec); err != nil {
+				return err
+			}
+		}
+	}
+	return s.finish()
+}
+
+// walk descends into the objects along the path. It returns true if the
+// decoder is positioned inside the list at the end of the path.
+func (s *Stream) walk(path []string) (bool, error) {
+	for i, name := range path {
+		tok, err := s.dec.Token()
+		if err != nil {
+			return false, err
+		}
+		if tok == nil {
+			return false, nil
+		} else if tok != json.Delim('{') {
+			return false, fmt.Errorf("expected object for field %q, got %v", name, tok)
+		}
+		s.depth++
+
+		found := false
+		for s.dec.More() {
+			key, err := readKey(s.dec)
+			if err != nil {
+				return false, err
+			}
+			if key == name {
+				found = true
+				break
+			}
+			if err := skipValue(s.dec); err != nil {
+				return false, err
+			}
+		}
+		if !found {
+			return false, fmt.Errorf("missing field %q", strings.Join(path[:i+1], "."))
+		}
+	}
+
+	tok, err := s.dec.Token()
+	if err != nil {
+		return false, err
+	}
+	if tok == nil {
+		return false, nil
+	} else if tok != json.Delim('[') {
+		return false, fmt.Errorf("expected list, got %v", tok)
+	}
+	return true, nil
+}
+
+// unwind skips the remaining fields of the objects opened by walk.
+func (s *Stream) unwind() error {
+	for ; s.depth > 0; s.depth-- {
+		for s.dec.More() {
+			if _, err := readKey(s.dec); err != nil {
+				return err
+			}
+			if err := skipValue(s.dec); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(s.dec, '}'); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finish reads the remaining top-level fields of the response.
+func (s *Stream) finish() error {
+	for s.dec.More() {
+		key, err := readKey(s.dec)
+		if err != nil {
+			return err
+		}
+		if key == "errors" {
+			err = s.decodeErrors()
+		} else {
+			err = skipValue(s.dec)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(s.dec, '}'); err != nil {
+		return err
+	}
+	s.done = true
+	return nil
+}
+
+func (s *Stream) decodeErrors() error {
+	return decodeErrorList(s.dec, &s.errs, s.maxErrors)
+}
+
+// Next decodes the next list element into v. It returns false when the end of
+// the list is reached or an error occurs.
+func (s *Stream) Next(v interface{}) bool {
+	if !s.inList || s.err != nil {
+		return false
+	}
+
+	if s.dec.More() {
+		if err := s.dec.Decode(v); err != nil {
+			s.err = decodeError(err)
+			return false
+		}
+		return true
+	}
+
+	s.inList = false
+	err := expectDelim(s.dec, ']')
+	if err == nil {
+		err = s.unwind()
+	}
+	if err == nil {
+		err = s.finish()
+	}
+	if err != nil {
+		s.err = decodeError(err)
+	}
+	return false
+}
+
+// Err returns the error which occurred while reading the stream, if any.
+//
+// Once the stream has been fully consumed, Err also returns the GraphQL errors
+// sent by the server.
+func (s *Stream) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+	if !s.done {
+		return nil
+	}
+	return responseError(s.resp, s.errs)
+}
+
+// Close releases resources associated with the stream.
+func (s *Stream) Close() error {
+	return s.body.Close()
+}
+
+func readKey(dec *json.Decoder) (string, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", fmt.Errorf("expected object key, got %v", tok)
+	}
+	return key, nil
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != want {
+		return fmt.Errorf("expected %v, got %v", want, tok)
+	}
+	return nil
+}
+
+// skipValue discards the next JSON value without holding it in memory.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
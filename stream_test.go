@@ -0,0 +1,152 @@
+package gqlclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// newTestClient creates a client for a server which always replies with the
+// specified JSON body.
+func newTestClient(t *testing.T, body string) *Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return New(srv.URL, nil)
+}
+
+func TestStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		path     string
+		want     []int
+		wantErrs int
+		wantFail bool
+	}{
+		{
+			name: "top-level",
+			body: `{"data":{"items":[1,2,3]}}`,
+			path: "items",
+			want: []int{1, 2, 3},
+		},
+		{
+			name: "nested",
+			body: `{"data":{"a":{"skip":{"x":[{}]},"b":{"items":[4,5]},"after":[1]},"c":true}}`,
+			path: "a.b.items",
+			want: []int{4, 5},
+		},
+		{
+			name: "empty list",
+			body: `{"data":{"items":[]}}`,
+			path: "items",
+		},
+		{
+			name: "null list",
+			body: `{"data":{"items":null}}`,
+			path: "items",
+		},
+		{
+			name: "null parent",
+			body: `{"data":{"a":null,"z":1}}`,
+			path: "a.items",
+		},
+		{
+			name:     "null data",
+			body:     `{"data":null,"errors":[{"message":"oops"}]}`,
+			path:     "items",
+			wantErrs: 1,
+		},
+		{
+			name:     "missing field",
+			body:     `{"data":{"other":[1]}}`,
+			path:     "items",
+			wantFail: true,
+		},
+		{
+			name:     "data prefix",
+			body:     `{"data":{"items":[1]}}`,
+			path:     "data.items",
+			wantFail: true,
+		},
+		{
+			name:     "errors before data",
+			body:     `{"errors":[{"message":"partial"}],"data":{"items":[1]}}`,
+			path:     "items",
+			want:     []int{1},
+			wantErrs: 1,
+		},
+		{
+			name:     "interleaved errors",
+			body:     `{"errors":[{"message":"a"}],"data":{"items":[1,2]},"extensions":{"x":[1]},"errors":[{"message":"b"}]}`,
+			path:     "items",
+			want:     []int{1, 2},
+			wantErrs: 2,
+		},
+		{
+			name:     "not a list",
+			body:     `{"data":{"items":{"a":1}}}`,
+			path:     "items",
+			wantFail: true,
+		},
+		{
+			name:     "not an object",
+			body:     `{"data":{"a":[1]}}`,
+			path:     "a.items",
+			wantFail: true,
+		},
+		{
+			name:     "bad element",
+			body:     `{"data":{"items":[1,"two"]}}`,
+			path:     "items",
+			want:     []int{1},
+			wantFail: true,
+		},
+		{
+			name:     "truncated",
+			body:     `{"data":{"items":[1,2`,
+			path:     "items",
+			want:     []int{1, 2},
+			wantFail: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := newTestClient(t, tc.body)
+			s, err := c.ExecuteStream(context.Background(), NewOperation("query { items }"), tc.path)
+			if err != nil {
+				if !tc.wantFail {
+					t.Fatalf("ExecuteStream() = %v", err)
+				}
+				return
+			}
+			defer s.Close()
+
+			var got []int
+			var v int
+			for s.Next(&v) {
+				got = append(got, v)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got elements %v, want %v", got, tc.want)
+			}
+
+			err = s.Err()
+			if tc.wantFail {
+				var gqlErr *Error
+				if err == nil || errors.As(err, &gqlErr) {
+					t.Errorf("Err() = %v, want a decoding error", err)
+				}
+			} else if len(s.errs) != tc.wantErrs || (err != nil) != (tc.wantErrs > 0) {
+				t.Errorf("Err() = %v, got %v GraphQL errors, want %v", err, len(s.errs), tc.wantErrs)
+			}
+		})
+	}
+}
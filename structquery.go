@@ -0,0 +1,376 @@
+package gqlclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// NewStructOperation creates a new GraphQL operation whose selection set is
+// derived from a Go struct.
+//
+// The operation type is "query" or "mutation". Each struct field is
+// selected, with the GraphQL field name derived from the Go field name (e.g.
+// "HTTPStatus" becomes "httpStatus"). The graphql struct tag overrides the
+// selection, and can contain arguments, an alias or an inline fragment:
+//
+//	User struct {
+//		Name string
+//	} `graphql:"user(login: $login)"`
+//	Other User `graphql:"other: user(login: \"emersion\")"`
+//	Bot struct {
+//		Owner string
+//	} `graphql:"... on Bot"`
+//
+// Embedded structs are selected inline. Struct types implementing
+// json.Unmarshaler are treated as scalars.
+//
+// Variable types are derived from the Go types of their values, e.g. a
+// string is a "String!" and a *int32 is an "Int". Named Go types use their
+// name, e.g. a value of type Color is a "Color!". Values implementing
+// GraphQLTyper can override their type.
+//
+// Responses must be decoded with DecodeStruct.
+func NewStructOperation(opType string, data interface{}, vars map[string]interface{}) (*Operation, error) {
+	t := reflect.TypeOf(data)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("gqlclient: expected a struct, got %T", data)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(opType)
+
+	names := make([]string, 0, len(vars))
+	for k := range vars {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	if len(names) > 0 {
+		sb.WriteString("(")
+		for i, k := range names {
+			typ, err := graphQLType(vars[k])
+			if err != nil {
+				return nil, fmt.Errorf("gqlclient: variable %q: %v", k, err)
+			}
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString("$" + k + ": " + typ)
+		}
+		sb.WriteString(")")
+	}
+
+	sb.WriteString(" ")
+	writeStructSelectionSet(&sb, t, 0)
+	sb.WriteString("\n")
+
+	op := NewOperation(sb.String())
+	for _, k := range names {
+		op.Var(k, vars[k])
+	}
+	return op, nil
+}
+
+// ExecuteQuery derives a query from the data struct with NewStructOperation,
+// executes it and decodes the result into data.
+func (c *Client) ExecuteQuery(ctx context.Context, data interface{}, vars map[string]interface{}) error {
+	return c.executeStruct(ctx, "query", data, vars)
+}
+
+// ExecuteMutation derives a mutation from the data struct with
+// NewStructOperation, executes it and decodes the result into data.
+func (c *Client) ExecuteMutation(ctx context.Context, data interface{}, vars map[string]interface{}) error {
+	return c.executeStruct(ctx, "mutation", data, vars)
+}
+
+func (c *Client) executeStruct(ctx context.Context, opType string, data interface{}, vars map[string]interface{}) error {
+	op, err := NewStructOperation(opType, data, vars)
+	if err != nil {
+		return err
+	}
+
+	var raw json.RawMessage
+	execErr := c.Execute(ctx, op, &raw)
+	if len(raw) > 0 {
+		if err := DecodeStruct(raw, data); err != nil {
+			return err
+		}
+	}
+	return execErr
+}
+
+// GraphQLTyper is implemented by variable values which specify their GraphQL
+// type, e.g. "[ID!]".
+type GraphQLTyper interface {
+	GraphQLType() string
+}
+
+var (
+	graphQLTyperType     = reflect.TypeOf((*GraphQLTyper)(nil)).Elem()
+	jsonUnmarshalerType  = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	rawMessageType       = reflect.TypeOf(json.RawMessage(nil))
+	graphQLBuiltinScalar = map[reflect.Kind]string{
+		reflect.Bool:    "Boolean",
+		reflect.Int:     "Int",
+		reflect.Int8:    "Int",
+		reflect.Int16:   "Int",
+		reflect.Int32:   "Int",
+		reflect.Int64:   "Int",
+		reflect.Uint:    "Int",
+		reflect.Uint8:   "Int",
+		reflect.Uint16:  "Int",
+		reflect.Uint32:  "Int",
+		reflect.Uint64:  "Int",
+		reflect.Float32: "Float",
+		reflect.Float64: "Float",
+		reflect.String:  "String",
+	}
+)
+
+func graphQLType(v interface{}) (string, error) {
+	if typer, ok := v.(GraphQLTyper); ok {
+		return typer.GraphQLType(), nil
+	}
+	if v == nil {
+		return "", fmt.Errorf("cannot infer the type of nil")
+	}
+	return graphQLTypeOf(reflect.TypeOf(v))
+}
+
+func graphQLTypeOf(t reflect.Type) (string, error) {
+	nonNull := "!"
+	if t.Kind() == reflect.Ptr {
+		nonNull = ""
+		t = t.Elem()
+	}
+
+	if t.Implements(graphQLTyperType) {
+		return reflect.Zero(t).Interface().(GraphQLTyper).GraphQLType(), nil
+	}
+
+	if t.Name() == "" || t.PkgPath() == "" {
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array:
+			elem, err := graphQLTypeOf(t.Elem())
+			if err != nil {
+				return "", err
+			}
+			return "[" + elem + "]" + nonNull, nil
+		}
+		if name, ok := graphQLBuiltinScalar[t.Kind()]; ok {
+			return name + nonNull, nil
+		}
+		return "", fmt.Errorf("cannot infer GraphQL type of %v", t)
+	}
+	return t.Name() + nonNull, nil
+}
+
+// structField describes how a Go struct field is selected.
+type structField struct {
+	// Selection text, e.g. "name" or "a: user(id: 1)"
+	selection string
+	// Key in the response object
+	key string
+	// If true, the field's sub-selections are in the parent object
+	inline bool
+}
+
+func parseStructField(f reflect.StructField) (structField, bool) {
+	if f.PkgPath != "" && !f.Anonymous {
+		return structField{}, false // unexported
+	}
+
+	tag, hasTag := f.Tag.Lookup("graphql")
+	if tag == "-" {
+		return structField{}, false
+	}
+	if !hasTag {
+		if f.Anonymous {
+			return structField{inline: true}, true
+		}
+		name := lowerCamel(f.Name)
+		return structField{selection: name, key: name}, true
+	}
+
+	tag = strings.TrimSpace(tag)
+	if strings.HasPrefix(tag, "...") {
+		return structField{selection: tag, inline: true}, true
+	}
+
+	// The response key is the alias if any, the field name otherwise
+	key := tag
+	if i := strings.IndexAny(key, "(@ {"); i >= 0 {
+		key = key[:i]
+	}
+	if i := strings.Index(key, ":"); i >= 0 {
+		key = key[:i]
+	}
+	return structField{selection: tag, key: strings.TrimSpace(key)}, true
+}
+
+func lowerCamel(s string) string {
+	runes := []rune(s)
+	n := 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+	if n > 1 && n < len(runes) {
+		if runes[n] == 's' && (n+1 == len(runes) || unicode.IsUpper(runes[n+1])) {
+			// Plural initialism, e.g. "URLs"
+		} else {
+			n-- // keep the start of the next word, e.g. "HTTPStatus"
+		}
+	}
+	for i := 0; i < n; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// selectionType returns the struct type to select fields from, or nil if the
+// type is a leaf.
+func selectionType(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			t = t.Elem()
+			continue
+		case reflect.Struct:
+			if reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+				return nil
+			}
+			return t
+		default:
+			return nil
+		}
+	}
+}
+
+func writeStructSelectionSet(sb *strings.Builder, t reflect.Type, depth int) {
+	sb.WriteString("{\n")
+	writeStructFields(sb, t, depth+1)
+	sb.WriteString(strings.Repeat("\t", depth) + "}")
+}
+
+func writeStructFields(sb *strings.Builder, t reflect.Type, depth int) {
+	indent := strings.Repeat("\t", depth)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		sf, ok := parseStructField(f)
+		if !ok {
+			continue
+		}
+
+		subType := selectionType(f.Type)
+		if sf.inline && sf.selection == "" {
+			if subType != nil {
+				writeStructFields(sb, subType, depth)
+			}
+			continue
+		}
+
+		sb.WriteString(indent + sf.selection)
+		if subType != nil {
+			sb.WriteString(" ")
+			writeStructSelectionSet(sb, subType, depth)
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// DecodeStruct decodes GraphQL response data into a struct used with
+// NewStructOperation.
+func DecodeStruct(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("gqlclient: DecodeStruct expects a non-nil pointer, got %T", v)
+	}
+	return decodeStructValue(data, rv.Elem())
+}
+
+func decodeStructValue(raw json.RawMessage, v reflect.Value) error {
+	if string(raw) == "null" {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	if v.Type() == rawMessageType || reflect.PtrTo(v.Type()).Implements(jsonUnmarshalerType) {
+		return json.Unmarshal(raw, v.Addr().Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeStructValue(raw, v.Elem())
+	case reflect.Slice:
+		var l []json.RawMessage
+		if err := json.Unmarshal(raw, &l); err != nil {
+			return err
+		}
+		s := reflect.MakeSlice(v.Type(), len(l), len(l))
+		for i, item := range l {
+			if err := decodeStructValue(item, s.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	case reflect.Array:
+		var l []json.RawMessage
+		if err := json.Unmarshal(raw, &l); err != nil {
+			return err
+		}
+		for i := 0; i < v.Len() && i < len(l); i++ {
+			if err := decodeStructValue(l[i], v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return err
+		}
+		return decodeStructFields(raw, obj, v)
+	default:
+		return json.Unmarshal(raw, v.Addr().Interface())
+	}
+}
+
+func decodeStructFields(raw json.RawMessage, obj map[string]json.RawMessage, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf, ok := parseStructField(t.Field(i))
+		if !ok {
+			continue
+		}
+
+		fv := v.Field(i)
+		if sf.inline {
+			// Inline fragments and embedded structs are decoded from the
+			// parent object
+			if err := decodeStructValue(raw, fv); err != nil {
+				return fmt.Errorf("in %v: %v", t.Field(i).Name, err)
+			}
+			continue
+		}
+
+		fieldRaw, ok := obj[sf.key]
+		if !ok {
+			continue
+		}
+		if err := decodeStructValue(fieldRaw, fv); err != nil {
+			return fmt.Errorf("in field %q: %v", sf.key, err)
+		}
+	}
+	return nil
+}
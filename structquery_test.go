@@ -0,0 +1,162 @@
+package gqlclient
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testColor string
+
+type testIDList []string
+
+func (testIDList) GraphQLType() string {
+	return "[ID!]"
+}
+
+type testNode struct {
+	ID string
+}
+
+type testRepo struct {
+	testNode
+	Name     string
+	HTTPURL  string
+	URLs     []string
+	Internal string `graphql:"-"`
+	private  string
+}
+
+type testQuery struct {
+	Me struct {
+		Login   string
+		Created Time
+		Repos   []*testRepo `graphql:"repositories(first: $n)"`
+	}
+	Other struct {
+		Login string
+	} `graphql:"other: user(login: $login)"`
+	Thing struct {
+		Typename string `graphql:"__typename"`
+		Bot      struct {
+			Owner string
+		} `graphql:"... on Bot"`
+	}
+}
+
+func TestNewStructOperation(t *testing.T) {
+	vars := map[string]interface{}{
+		"n":      int32(10),
+		"login":  "emersion",
+		"after":  (*string)(nil),
+		"colors": []testColor{"red"},
+		"ids":    testIDList{"1"},
+	}
+	op, err := NewStructOperation("query", &testQuery{}, vars)
+	if err != nil {
+		t.Fatalf("NewStructOperation() = %v", err)
+	}
+
+	want := `query($after: String, $colors: [testColor!]!, $ids: [ID!], $login: String!, $n: Int!) {
+	me {
+		login
+		created
+		repositories(first: $n) {
+			id
+			name
+			httpurl
+			urls
+		}
+	}
+	other: user(login: $login) {
+		login
+	}
+	thing {
+		__typename
+		... on Bot {
+			owner
+		}
+	}
+}
+`
+	if op.query != want {
+		t.Errorf("NewStructOperation() = \n%v\nwant:\n%v", op.query, want)
+	}
+}
+
+func TestNewStructOperationErrors(t *testing.T) {
+	if _, err := NewStructOperation("query", "not a struct", nil); err == nil {
+		t.Errorf("NewStructOperation(string) succeeded")
+	}
+	if _, err := NewStructOperation("query", &testQuery{}, map[string]interface{}{"x": nil}); err == nil {
+		t.Errorf("NewStructOperation() with a nil variable succeeded")
+	}
+	if _, err := NewStructOperation("query", &testQuery{}, map[string]interface{}{"x": map[string]int{}}); err == nil {
+		t.Errorf("NewStructOperation() with a map variable succeeded")
+	}
+}
+
+func TestLowerCamel(t *testing.T) {
+	tests := map[string]string{
+		"Name":       "name",
+		"ID":         "id",
+		"HTTPStatus": "httpStatus",
+		"URLs":       "urls",
+		"URLsByID":   "urlsByID",
+		"APIKey":     "apiKey",
+		"X":          "x",
+		"already":    "already",
+	}
+	for in, want := range tests {
+		if got := lowerCamel(in); got != want {
+			t.Errorf("lowerCamel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDecodeStruct(t *testing.T) {
+	data := `{
+		"me": {
+			"login": "emersion",
+			"created": "2006-01-02T15:04:05Z",
+			"repositories": [
+				{"id": "1", "name": "gqlclient", "httpurl": "https://example.org", "urls": ["a", "b"]},
+				null
+			]
+		},
+		"other": {"login": "sircmpwn"},
+		"thing": {"__typename": "Bot", "owner": "emersion"}
+	}`
+
+	var q testQuery
+	if err := DecodeStruct([]byte(data), &q); err != nil {
+		t.Fatalf("DecodeStruct() = %v", err)
+	}
+
+	if q.Me.Login != "emersion" || !q.Me.Created.Equal(time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)) {
+		t.Errorf("me = %+v", q.Me)
+	}
+	wantRepos := []*testRepo{
+		{testNode: testNode{ID: "1"}, Name: "gqlclient", HTTPURL: "https://example.org", URLs: []string{"a", "b"}},
+		nil,
+	}
+	if !reflect.DeepEqual(q.Me.Repos, wantRepos) {
+		t.Errorf("repos = %+v, want %+v", *q.Me.Repos[0], *wantRepos[0])
+	}
+	if q.Other.Login != "sircmpwn" {
+		t.Errorf("other = %+v", q.Other)
+	}
+	if q.Thing.Typename != "Bot" || q.Thing.Bot.Owner != "emersion" {
+		t.Errorf("thing = %+v", q.Thing)
+	}
+}
+
+func TestDecodeStructErrors(t *testing.T) {
+	var q testQuery
+	if err := DecodeStruct([]byte(`{"me": {"login": 42}}`), &q); err == nil {
+		t.Errorf("DecodeStruct() with a type mismatch succeeded")
+	}
+	if err := DecodeStruct([]byte(`{}`), q); err == nil {
+		t.Errorf("DecodeStruct() with a non-pointer succeeded")
+	}
+}
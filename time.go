@@ -2,26 +2,164 @@ package gqlclient
 
 import (
 	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
-const timeLayout = time.RFC3339Nano
+// TimeFormat describes how timestamps are formatted and parsed.
+type TimeFormat struct {
+	// Layout used to format timestamps, as accepted by time.Time.Format. It
+	// is also accepted when parsing. If empty, time.RFC3339Nano is used.
+	Layout string
+	// Additional layouts accepted when parsing. Timestamps without a time
+	// zone are interpreted as UTC.
+	ParseLayouts []string
+	// If true, JSON numbers are accepted when parsing and interpreted as Unix
+	// timestamps. Values whose absolute value is lower than 1e11 are
+	// interpreted as seconds, others as milliseconds.
+	Epoch bool
+}
+
+// defaultTimeFormat is the format used by Time.
+var defaultTimeFormat = &TimeFormat{
+	Layout: time.RFC3339Nano,
+	ParseLayouts: []string{
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05Z07:00",
+		"2006-01-02 15:04:05",
+	},
+	Epoch: true,
+}
+
+// MarshalTime encodes a timestamp to JSON. The zero value is encoded as null.
+func (f *TimeFormat) MarshalTime(t time.Time) ([]byte, error) {
+	var v interface{}
+	if !t.IsZero() {
+		v = t.Format(f.layout())
+	}
+	return json.Marshal(v)
+}
 
+// UnmarshalTime decodes a timestamp from JSON. null is decoded as the zero
+// value.
+func (f *TimeFormat) UnmarshalTime(b []byte) (time.Time, error) {
+	if string(b) == "null" {
+		return time.Time{}, nil
+	}
+
+	if f.Epoch && len(b) > 0 && b[0] != '"' {
+		var n json.Number
+		if err := json.Unmarshal(b, &n); err != nil {
+			return time.Time{}, err
+		}
+		if i, err := n.Int64(); err == nil {
+			if i > -1e11 && i < 1e11 {
+				return time.Unix(i, 0).UTC(), nil
+			}
+			return time.UnixMilli(i).UTC(), nil
+		}
+		v, err := n.Float64()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("gqlclient: invalid Unix timestamp %v", n)
+		}
+		if v > -1e11 && v < 1e11 {
+			return time.Unix(0, int64(v*float64(time.Second))).UTC(), nil
+		}
+		return time.Unix(0, int64(v*float64(time.Millisecond))).UTC(), nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return time.Time{}, err
+	}
+
+	t, err := time.Parse(f.layout(), s)
+	if err == nil {
+		return t, nil
+	}
+	for _, layout := range f.ParseLayouts {
+		if t, parseErr := time.Parse(layout, s); parseErr == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func (f *TimeFormat) layout() string {
+	if f.Layout == "" {
+		return time.RFC3339Nano
+	}
+	return f.Layout
+}
+
+// Scalar returns a scalar codec for time.Time using this format. It can be
+// passed to RegisterScalar to use a different format for a specific GraphQL
+// scalar, along with "gqlclientgen -C <scalar>=time.Time".
+func (f *TimeFormat) Scalar() *Scalar {
+	return &Scalar{
+		GoType:           "time.Time",
+		RecognizableZero: true,
+		Marshal: func(v interface{}) ([]byte, error) {
+			return f.MarshalTime(*v.(*time.Time))
+		},
+		Unmarshal: func(b []byte, v interface{}) error {
+			t, err := f.UnmarshalTime(b)
+			if err != nil {
+				return err
+			}
+			*v.(*time.Time) = t
+			return nil
+		},
+	}
+}
+
+// Time is a timestamp.
+//
+// By default, timestamps are formatted as RFC 3339, and parsed from RFC 3339,
+// a few common variants and Unix timestamps. Scalars using another format can
+// be mapped to time.Time with TimeFormat.Scalar instead.
+//
+// The zero value is encoded as null.
 type Time struct {
 	time.Time
 }
 
+// MarshalJSON implements json.Marshaler.
 func (t Time) MarshalJSON() ([]byte, error) {
+	return defaultTimeFormat.MarshalTime(t.Time)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Time) UnmarshalJSON(b []byte) error {
+	var err error
+	t.Time, err = defaultTimeFormat.UnmarshalTime(b)
+	return err
+}
+
+const dateLayout = "2006-01-02"
+
+// Date is a calendar date, formatted as YYYY-MM-DD.
+//
+// The zero value is encoded as null.
+type Date struct {
+	time.Time
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Date) MarshalJSON() ([]byte, error) {
 	var v interface{}
-	if !t.IsZero() {
-		v = t.Format(timeLayout)
+	if !d.IsZero() {
+		v = d.Format(dateLayout)
 	}
 	return json.Marshal(v)
 }
 
-func (t *Time) UnmarshalJSON(b []byte) error {
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Date) UnmarshalJSON(b []byte) error {
 	if string(b) == "null" {
-		t.Time = time.Time{}
+		d.Time = time.Time{}
 		return nil
 	}
 
@@ -31,6 +169,120 @@ func (t *Time) UnmarshalJSON(b []byte) error {
 	}
 
 	var err error
-	t.Time, err = time.Parse(timeLayout, s)
+	d.Time, err = time.Parse(dateLayout, s)
 	return err
 }
+
+// Duration is an ISO 8601 duration, e.g. "PT1H30M".
+//
+// Only durations with a fixed length are supported: years and months are
+// rejected, days are 24 hours long. The zero value is a valid duration,
+// encoded as "PT0S". Use a pointer to represent null.
+type Duration struct {
+	time.Duration
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(formatDuration(d.Duration))
+}
+
+// UnmarshalJSON implements json.Unmarshaler. null is decoded as the zero
+// value.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		d.Duration = 0
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	var err error
+	d.Duration, err = parseDuration(s)
+	return err
+}
+
+func formatDuration(d time.Duration) string {
+	var sb strings.Builder
+	if d < 0 {
+		sb.WriteByte('-')
+		d = -d
+	}
+	sb.WriteString("PT")
+	if h := d / time.Hour; h > 0 {
+		fmt.Fprintf(&sb, "%dH", h)
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		fmt.Fprintf(&sb, "%dM", m)
+		d -= m * time.Minute
+	}
+	if d > 0 || sb.Len() <= 3 {
+		s := strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+		sb.WriteString(s + "S")
+	}
+	return sb.String()
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	orig := s
+	neg := false
+	if strings.HasPrefix(s, "-") {
+		neg = true
+		s = s[1:]
+	} else if strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") || len(s) == 1 {
+		return 0, fmt.Errorf("gqlclient: invalid ISO 8601 duration %q", orig)
+	}
+	s = s[1:]
+
+	var d time.Duration
+	inTime := false
+	for s != "" {
+		if s[0] == 'T' {
+			if inTime || len(s) == 1 {
+				return 0, fmt.Errorf("gqlclient: invalid ISO 8601 duration %q", orig)
+			}
+			inTime = true
+			s = s[1:]
+			continue
+		}
+
+		i := strings.IndexAny(s, "YMWDHS")
+		if i <= 0 {
+			return 0, fmt.Errorf("gqlclient: invalid ISO 8601 duration %q", orig)
+		}
+		v, err := strconv.ParseFloat(strings.Replace(s[:i], ",", ".", 1), 64)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("gqlclient: invalid ISO 8601 duration %q", orig)
+		}
+
+		var unit time.Duration
+		switch designator := s[i]; {
+		case !inTime && designator == 'W':
+			unit = 7 * 24 * time.Hour
+		case !inTime && designator == 'D':
+			unit = 24 * time.Hour
+		case inTime && designator == 'H':
+			unit = time.Hour
+		case inTime && designator == 'M':
+			unit = time.Minute
+		case inTime && designator == 'S':
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("gqlclient: unsupported ISO 8601 duration %q", orig)
+		}
+		d += time.Duration(v * float64(unit))
+		s = s[i+1:]
+	}
+
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
@@ -0,0 +1,232 @@
+package gqlclient
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    time.Duration
+		wantErr bool
+	}{
+		{s: "PT0S", want: 0},
+		{s: "PT1H30M", want: 90 * time.Minute},
+		{s: "PT1.5S", want: 1500 * time.Millisecond},
+		{s: "PT0,5S", want: 500 * time.Millisecond},
+		{s: "P1DT0.5S", want: 24*time.Hour + 500*time.Millisecond},
+		{s: "P1D", want: 24 * time.Hour},
+		{s: "P1.5D", want: 36 * time.Hour},
+		{s: "P2W", want: 14 * 24 * time.Hour},
+		{s: "PT36H", want: 36 * time.Hour},
+		{s: "PT1M", want: time.Minute},
+		{s: "-PT1M", want: -time.Minute},
+		{s: "+PT1M", want: time.Minute},
+		{s: "P1M", wantErr: true},
+		{s: "P1Y", wantErr: true},
+		{s: "PT1D", wantErr: true},
+		{s: "P1H", wantErr: true},
+		{s: "P", wantErr: true},
+		{s: "PT", wantErr: true},
+		{s: "P1DT", wantErr: true},
+		{s: "PTT1H", wantErr: true},
+		{s: "P-1D", wantErr: true},
+		{s: "PTH", wantErr: true},
+		{s: "PT1", wantErr: true},
+		{s: "1H", wantErr: true},
+		{s: "", wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := parseDuration(tc.s)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("parseDuration(%q) = %v, want an error", tc.s, got)
+			}
+		} else if err != nil {
+			t.Errorf("parseDuration(%q) = %v", tc.s, err)
+		} else if got != tc.want {
+			t.Errorf("parseDuration(%q) = %v, want %v", tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 0, want: "PT0S"},
+		{d: 90 * time.Minute, want: "PT1H30M"},
+		{d: 25 * time.Hour, want: "PT25H"},
+		{d: time.Hour + 500*time.Millisecond, want: "PT1H0.5S"},
+		{d: 1500 * time.Millisecond, want: "PT1.5S"},
+		{d: time.Nanosecond, want: "PT0.000000001S"},
+		{d: -time.Minute, want: "-PT1M"},
+	}
+	for _, tc := range tests {
+		got := formatDuration(tc.d)
+		if got != tc.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tc.d, got, tc.want)
+		}
+		if d, err := parseDuration(got); err != nil || d != tc.d {
+			t.Errorf("parseDuration(%q) = %v, %v, want %v", got, d, err, tc.d)
+		}
+	}
+}
+
+func TestTimeFormatUnmarshal(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: `"2006-01-02T15:04:05Z"`, want: time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{in: `"2006-01-02T15:04:05.5+01:00"`, want: time.Date(2006, 1, 2, 14, 4, 5, 5e8, time.UTC)},
+		{in: `"2006-01-02T15:04:05"`, want: time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{in: `"2006-01-02 15:04:05"`, want: time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{in: `1136214245`, want: time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{in: `1136214245500`, want: time.Date(2006, 1, 2, 15, 4, 5, 5e8, time.UTC)},
+		{in: `1136214245.5`, want: time.Date(2006, 1, 2, 15, 4, 5, 5e8, time.UTC)},
+		{in: `null`, want: time.Time{}},
+		{in: `"yesterday"`, wantErr: true},
+		{in: `true`, wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := defaultTimeFormat.UnmarshalTime([]byte(tc.in))
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("UnmarshalTime(%v) = %v, want an error", tc.in, got)
+			}
+		} else if err != nil {
+			t.Errorf("UnmarshalTime(%v) = %v", tc.in, err)
+		} else if !got.Equal(tc.want) {
+			t.Errorf("UnmarshalTime(%v) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestTimeFormatScalar(t *testing.T) {
+	RegisterScalar("TestTime", (&TimeFormat{Layout: "02/01/2006 15:04"}).Scalar())
+
+	ts := time.Date(2006, 1, 2, 15, 4, 0, 0, time.UTC)
+	b, err := MarshalScalar("TestTime", &ts)
+	if err != nil {
+		t.Fatalf("MarshalScalar() = %v", err)
+	}
+	if want := `"02/01/2006 15:04"`; string(b) != want {
+		t.Errorf("MarshalScalar() = %s, want %s", b, want)
+	}
+
+	var got time.Time
+	if err := UnmarshalScalar("TestTime", b, &got); err != nil {
+		t.Fatalf("UnmarshalScalar() = %v", err)
+	} else if !got.Equal(ts) {
+		t.Errorf("UnmarshalScalar() = %v, want %v", got, ts)
+	}
+
+	if err := UnmarshalScalar("TestTime", []byte(`"2006-01-02T15:04:00Z"`), &got); err == nil {
+		t.Errorf("UnmarshalScalar() with another layout succeeded")
+	}
+	if err := UnmarshalScalar("TestTime", []byte(`1136214240`), &got); err == nil {
+		t.Errorf("UnmarshalScalar() with a Unix timestamp succeeded without Epoch")
+	}
+}
+
+func TestTimeFormatEmptyLayout(t *testing.T) {
+	var f TimeFormat
+	ts := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	b, err := f.MarshalTime(ts)
+	if err != nil {
+		t.Fatalf("MarshalTime() = %v", err)
+	}
+	if want := `"2006-01-02T15:04:05Z"`; string(b) != want {
+		t.Errorf("MarshalTime() = %s, want %s", b, want)
+	}
+	if got, err := f.UnmarshalTime(b); err != nil {
+		t.Errorf("UnmarshalTime() = %v", err)
+	} else if !got.Equal(ts) {
+		t.Errorf("UnmarshalTime() = %v, want %v", got, ts)
+	}
+}
+
+func TestDurationJSON(t *testing.T) {
+	tests := []struct {
+		d    Duration
+		want string
+	}{
+		{d: Duration{0}, want: `"PT0S"`},
+		{d: Duration{90 * time.Minute}, want: `"PT1H30M"`},
+		{d: Duration{-time.Second}, want: `"-PT1S"`},
+	}
+	for _, tc := range tests {
+		b, err := json.Marshal(tc.d)
+		if err != nil || string(b) != tc.want {
+			t.Errorf("Marshal(%v) = %s, %v, want %v", tc.d, b, err, tc.want)
+		}
+		var got Duration
+		if err := json.Unmarshal(b, &got); err != nil || got != tc.d {
+			t.Errorf("Unmarshal(%s) = %v, %v, want %v", b, got, err, tc.d)
+		}
+	}
+
+	// Null is only encoded by a nil pointer
+	var v struct {
+		D *Duration
+	}
+	if err := json.Unmarshal([]byte(`{"D":"PT0S"}`), &v); err != nil || v.D == nil || v.D.Duration != 0 {
+		t.Errorf("Unmarshal(PT0S) = %v, %v, want a zero duration", v.D, err)
+	}
+	if err := json.Unmarshal([]byte(`{"D":null}`), &v); err != nil || v.D != nil {
+		t.Errorf("Unmarshal(null) = %v, %v, want nil", v.D, err)
+	}
+
+	for _, in := range []string{`"P1M"`, `42`, `"PT"`} {
+		var d Duration
+		if err := json.Unmarshal([]byte(in), &d); err == nil {
+			t.Errorf("Unmarshal(%v) = %v, want an error", in, d)
+		}
+	}
+}
+
+func TestDateJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: `"2006-01-02"`, want: time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{in: `"2024-02-29"`, want: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{in: `null`, want: time.Time{}},
+		{in: `"2023-02-29"`, wantErr: true},
+		{in: `"2006-01-02T15:04:05Z"`, wantErr: true},
+		{in: `"02/01/2006"`, wantErr: true},
+		{in: `20060102`, wantErr: true},
+	}
+	for _, tc := range tests {
+		var d Date
+		err := json.Unmarshal([]byte(tc.in), &d)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("Unmarshal(%v) = %v, want an error", tc.in, d)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("Unmarshal(%v) = %v", tc.in, err)
+			continue
+		}
+		if !d.Equal(tc.want) {
+			t.Errorf("Unmarshal(%v) = %v, want %v", tc.in, d, tc.want)
+		}
+		if b, err := json.Marshal(d); err != nil || string(b) != tc.in {
+			t.Errorf("Marshal(Unmarshal(%v)) = %s, %v", tc.in, b, err)
+		}
+	}
+
+	// The time of day is dropped
+	b, err := json.Marshal(Date{time.Date(2006, 1, 2, 23, 59, 0, 0, time.UTC)})
+	if err != nil || string(b) != `"2006-01-02"` {
+		t.Errorf("Marshal() = %s, %v, want %q", b, err, "2006-01-02")
+	}
+}
@@ -0,0 +1,88 @@
+package gqlclient
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// InjectTypename adds a __typename field to the selection sets of a query
+// document, so that the concrete type of abstract values can be determined.
+//
+// If schema is nil, __typename is added to all selection sets except the
+// operation ones. Otherwise, the document must have been validated against
+// the schema, and __typename is only added to selection sets on interface and
+// union types.
+func InjectTypename(doc *ast.QueryDocument, schema *ast.Schema) {
+	for _, op := range doc.Operations {
+		injectTypename(op.SelectionSet, schema)
+	}
+	for _, frag := range doc.Fragments {
+		if isAbstractType(schema, frag.TypeCondition) {
+			frag.SelectionSet = addTypenameField(frag.SelectionSet)
+		}
+		injectTypename(frag.SelectionSet, schema)
+	}
+}
+
+func injectTypename(selSet ast.SelectionSet, schema *ast.Schema) {
+	for _, sel := range selSet {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if len(sel.SelectionSet) == 0 {
+				continue
+			}
+			if schema == nil || (sel.Definition != nil && isAbstractType(schema, sel.Definition.Type.Name())) {
+				sel.SelectionSet = addTypenameField(sel.SelectionSet)
+			}
+			injectTypename(sel.SelectionSet, schema)
+		case *ast.InlineFragment:
+			injectTypename(sel.SelectionSet, schema)
+		case *ast.FragmentSpread:
+			// Fragment definitions are handled separately
+		}
+	}
+}
+
+func isAbstractType(schema *ast.Schema, name string) bool {
+	if schema == nil {
+		return true
+	}
+	def := schema.Types[name]
+	return def != nil && def.IsAbstractType()
+}
+
+func addTypenameField(selSet ast.SelectionSet) ast.SelectionSet {
+	for _, sel := range selSet {
+		if field, ok := sel.(*ast.Field); ok && field.Name == "__typename" && (field.Alias == "" || field.Alias == field.Name) {
+			return selSet
+		}
+	}
+	return append(ast.SelectionSet{&ast.Field{Alias: "__typename", Name: "__typename"}}, selSet...)
+}
+
+// injectTypenameQuery adds __typename to all nested selection sets of a query
+// string.
+func injectTypenameQuery(query string) (string, error) {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil {
+		return "", fmt.Errorf("gqlclient: failed to inject __typename: %v", err)
+	}
+	InjectTypename(doc, nil)
+
+	var sb strings.Builder
+	formatter.NewFormatter(&sb).FormatQueryDocument(doc)
+	return sb.String(), nil
+}
+
+// typenameQuery returns the operation's query with __typename injected. The
+// result is computed once per operation.
+func (op *Operation) typenameQuery() (string, error) {
+	op.typename.once.Do(func() {
+		op.typename.query, op.typename.err = injectTypenameQuery(op.query)
+	})
+	return op.typename.query, op.typename.err
+}
@@ -0,0 +1,91 @@
+package gqlclient
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+)
+
+func formatQueryDocument(doc *ast.QueryDocument) string {
+	var sb strings.Builder
+	formatter.NewFormatter(&sb).FormatQueryDocument(doc)
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+func TestInjectTypename(t *testing.T) {
+	schema := gqlparser.MustLoadSchema(&ast.Source{Input: `
+		interface Node { id: ID! }
+		type User implements Node { id: ID! name: String! friends: [User!]! }
+		union Result = User
+		type Query {
+			node: Node
+			me: User
+			search: [Result!]!
+		}
+	`})
+	doc, gqlErr := gqlparser.LoadQuery(schema, `
+		query {
+			node { id ...userFields }
+			me { name friends { name } }
+			search { ... on User { name } }
+		}
+		fragment userFields on User { name }
+	`)
+	if gqlErr != nil {
+		t.Fatal(gqlErr)
+	}
+
+	InjectTypename(doc, schema)
+	want := "query { node { __typename id ... userFields } me { name friends { name } } search { __typename ... on User { name } } } fragment userFields on User { name }"
+	if got := formatQueryDocument(doc); got != want {
+		t.Errorf("InjectTypename() = %q, want %q", got, want)
+	}
+
+	// Injecting twice doesn't duplicate the field
+	InjectTypename(doc, schema)
+	if got := formatQueryDocument(doc); got != want {
+		t.Errorf("InjectTypename() twice = %q, want %q", got, want)
+	}
+}
+
+func TestInjectTypenameQuery(t *testing.T) {
+	got, err := injectTypenameQuery(`query { me { name friends { __typename name } } }`)
+	if err != nil {
+		t.Fatalf("injectTypenameQuery() = %v", err)
+	}
+	got = strings.Join(strings.Fields(got), " ")
+	want := "query { me { __typename name friends { __typename name } } }"
+	if got != want {
+		t.Errorf("injectTypenameQuery() = %q, want %q", got, want)
+	}
+
+	if _, err := injectTypenameQuery(`query { me {`); err == nil {
+		t.Errorf("injectTypenameQuery() with an invalid query succeeded")
+	}
+}
+
+func TestClientInjectTypename(t *testing.T) {
+	c := newTestClient(t, `{"data":{"me":{"__typename":"User"}}}`)
+	c.InjectTypename = true
+
+	op := NewOperation(`query { me { name } }`)
+	if err := c.Execute(context.Background(), op, nil); err != nil {
+		t.Fatalf("Execute() = %v", err)
+	}
+	cached := op.typename.query
+	if !strings.Contains(cached, "__typename") {
+		t.Errorf("query %q doesn't contain __typename", cached)
+	}
+	if err := c.Execute(context.Background(), op, nil); err != nil {
+		t.Fatalf("Execute() = %v", err)
+	}
+
+	op = NewOperation(`query { me {`)
+	if err := c.Execute(context.Background(), op, nil); err == nil {
+		t.Errorf("Execute() with an invalid query succeeded")
+	}
+}
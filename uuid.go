@@ -0,0 +1,60 @@
+package gqlclient
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// UUID is a universally unique identifier, formatted as
+// xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx.
+//
+// The zero value is the nil UUID, encoded as
+// "00000000-0000-0000-0000-000000000000". Use a pointer to represent null.
+type UUID [16]byte
+
+// ParseUUID parses a UUID in its canonical textual representation.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, fmt.Errorf("gqlclient: invalid UUID %q", s)
+	}
+	src := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:36]
+	if _, err := hex.Decode(u[:], []byte(src)); err != nil {
+		return u, fmt.Errorf("gqlclient: invalid UUID %q", s)
+	}
+	return u, nil
+}
+
+// IsZero reports whether u is the zero UUID.
+func (u UUID) IsZero() bool {
+	return u == UUID{}
+}
+
+// String returns the canonical textual representation of the UUID.
+func (u UUID) String() string {
+	s := hex.EncodeToString(u[:])
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:32]
+}
+
+// MarshalJSON implements json.Marshaler.
+func (u UUID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. null is decoded as the nil UUID.
+func (u *UUID) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*u = UUID{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	var err error
+	*u, err = ParseUUID(s)
+	return err
+}
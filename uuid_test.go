@@ -0,0 +1,58 @@
+package gqlclient
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUUIDJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: `"123e4567-e89b-12d3-a456-426614174000"`, want: `"123e4567-e89b-12d3-a456-426614174000"`},
+		{in: `"123E4567-E89B-12D3-A456-426614174000"`, want: `"123e4567-e89b-12d3-a456-426614174000"`},
+		{in: `"00000000-0000-0000-0000-000000000000"`, want: `"00000000-0000-0000-0000-000000000000"`},
+		{in: `null`, want: `"00000000-0000-0000-0000-000000000000"`},
+		{in: `"123e4567e89b12d3a456426614174000"`, wantErr: true},
+		{in: `"123e4567-e89b-12d3-a456-42661417400"`, wantErr: true},
+		{in: `"123e4567-e89b-12d3-a456_426614174000"`, wantErr: true},
+		{in: `"g23e4567-e89b-12d3-a456-426614174000"`, wantErr: true},
+		{in: `42`, wantErr: true},
+	}
+	for _, tc := range tests {
+		var u UUID
+		err := json.Unmarshal([]byte(tc.in), &u)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("Unmarshal(%v) = %v, want an error", tc.in, u)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("Unmarshal(%v) = %v", tc.in, err)
+			continue
+		}
+		if b, err := json.Marshal(u); err != nil || string(b) != tc.want {
+			t.Errorf("Marshal(Unmarshal(%v)) = %s, %v, want %v", tc.in, b, err, tc.want)
+		}
+	}
+}
+
+func TestParseUUID(t *testing.T) {
+	const s = "123e4567-e89b-12d3-a456-426614174000"
+	u, err := ParseUUID(s)
+	if err != nil {
+		t.Fatalf("ParseUUID() = %v", err)
+	}
+	want := UUID{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+	if u != want {
+		t.Errorf("ParseUUID() = %x, want %x", u[:], want[:])
+	}
+	if u.String() != s {
+		t.Errorf("String() = %q, want %q", u.String(), s)
+	}
+	if u.IsZero() || !(UUID{}).IsZero() {
+		t.Errorf("IsZero() is wrong")
+	}
+}